@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/shuldan/config"
+)
+
+func runRender(args []string, stdout, stderr io.Writer) int {
+	fs, sf := newFlagSet("render", stderr)
+	format := fs.String("format", "yaml", "output format: yaml, json, toml, env")
+	sources := fs.Bool("sources", false, "annotate values with their source")
+	if _, ok := parseFlags(fs, args, 0); !ok {
+		return exitUsage
+	}
+
+	cfg, err := sf.load()
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	opts := []config.EncodeOption{config.WithEnvPrefix(sf.envPrefix)}
+	if !sf.showSecrets {
+		opts = append(opts, config.WithRedaction())
+	}
+	if *sources {
+		opts = append(opts, config.WithProvenance())
+	}
+	if err = cfg.Encode(stdout, config.Format(*format), opts...); err != nil {
+		return fail(stderr, err)
+	}
+	return exitOK
+}
+
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	fs, sf := newFlagSet("validate", stderr)
+	schemaPath := fs.String("schema", "", "JSON Schema file")
+	coerce := fs.Bool("coerce", false, "accept strings that convert to the schema type, e.g. values from env")
+	if _, ok := parseFlags(fs, args, 0); !ok {
+		return exitUsage
+	}
+	if *schemaPath == "" {
+		fmt.Fprintln(stderr, "configctl validate: -schema is required")
+		return exitUsage
+	}
+
+	schema, err := os.ReadFile(*schemaPath)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	if err := json.Unmarshal(schema, &config.Schema{}); err != nil {
+		return fail(stderr, fmt.Errorf("invalid schema %s: %w", *schemaPath, err))
+	}
+
+	var opts []config.SchemaOption
+	if *coerce {
+		opts = append(opts, config.WithTypeCoercion())
+	}
+	_, err = sf.load(config.WithSchema(schema, opts...))
+	var ve *config.ValidationError
+	if errors.As(err, &ve) {
+		for _, v := range ve.Details {
+			fmt.Fprintf(stdout, "%s: %s\n", v.Key, v.Message)
+		}
+		return exitFailure
+	}
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	fmt.Fprintln(stdout, "ok")
+	return exitOK
+}
+
+func runGet(args []string, stdout, stderr io.Writer) int {
+	fs, sf := newFlagSet("get", stderr)
+	rest, ok := parseFlags(fs, args, 1)
+	if !ok {
+		return exitUsage
+	}
+	key := rest[0]
+
+	cfg, err := sf.load()
+	if err != nil {
+		return fail(stderr, err)
+	}
+	if !cfg.Has(key) {
+		return fail(stderr, fmt.Errorf("key %q not found", key))
+	}
+
+	out, err := formatValue(sf.value(cfg, key))
+	if err != nil {
+		return fail(stderr, err)
+	}
+	fmt.Fprintln(stdout, out)
+	return exitOK
+}
+
+func runExplain(args []string, stdout, stderr io.Writer) int {
+	fs, sf := newFlagSet("explain", stderr)
+	rest, ok := parseFlags(fs, args, 1)
+	if !ok {
+		return exitUsage
+	}
+	key := rest[0]
+
+	cfg, err := sf.load()
+	if err != nil {
+		return fail(stderr, err)
+	}
+	if !cfg.Has(key) {
+		return fail(stderr, fmt.Errorf("key %q not found", key))
+	}
+
+	leaves := make(map[string]any)
+	flatten(key, sf.value(cfg, key), leaves)
+
+	for _, k := range slices.Sorted(maps.Keys(leaves)) {
+		out, err := formatValue(leaves[k])
+		if err != nil {
+			return fail(stderr, err)
+		}
+		src := cfg.Source(k)
+		if src == "" {
+			src = "unknown"
+		}
+		fmt.Fprintf(stdout, "%s = %s\n    source: %s\n", k, out, src)
+		if cfg.IsSensitive(k) {
+			fmt.Fprintln(stdout, "    sensitive: true")
+		}
+	}
+	return exitOK
+}
+
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	fs, sf := newFlagSet("diff", stderr)
+	loose := fs.Bool("loose", false, "treat 8080 and \"8080\" as equal")
+	rest, ok := parseFlags(fs, args, 2)
+	if !ok {
+		return exitUsage
+	}
+
+	var sides [2]*config.Config
+	for i, path := range rest {
+		side := *sf
+		side.files = stringList{path}
+		side.envPrefix = ""
+		cfg, err := side.load()
+		if err != nil {
+			return fail(stderr, err)
+		}
+		sides[i] = cfg
+	}
+
+	var opts []config.DiffOption
+	if *loose {
+		opts = append(opts, config.WithLooseTypes())
+	}
+	if sf.showSecrets {
+		opts = append(opts, config.WithRevealedSecrets())
+	}
+
+	changes := config.Diff(sides[0], sides[1], opts...)
+	for _, c := range changes {
+		fmt.Fprintln(stdout, c)
+	}
+
+	if len(changes) > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+func (sf *sourceFlags) value(cfg *config.Config, key string) any {
+	if sf.showSecrets {
+		return cfg.Get(key)
+	}
+	return config.FromMap(cfg.Redacted()).Get(key)
+}
+
+func flatten(prefix string, v any, out map[string]any) {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) == 0 {
+		out[prefix] = v
+		return
+	}
+	for k, item := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		flatten(key, item, out)
+	}
+}
+
+func formatValue(v any) (string, error) {
+	switch val := v.(type) {
+	case string:
+		return val, nil
+	case nil:
+		return "null", nil
+	case map[string]any, []any:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	default:
+		return fmt.Sprint(val), nil
+	}
+}
+
+func runConvert(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("configctl convert", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	from := fs.String("from", "", "input format: yaml, json, toml, env; inferred from the file extension")
+	to := fs.String("to", "", "output format: yaml, json, toml, env")
+	prefix := fs.String("prefix", "", "environment variable prefix")
+	types := fs.Bool("types", false, "parse env values into numbers and booleans")
+	rest, ok := parseFlags(fs, args, -1)
+	if !ok {
+		return exitUsage
+	}
+	if len(rest) > 1 || *to == "" {
+		fmt.Fprintln(stderr, "configctl convert: usage: convert -to FORMAT [-from FORMAT] [file|-]")
+		return exitUsage
+	}
+
+	path := "-"
+	if len(rest) == 1 {
+		path = rest[0]
+	}
+	if *from == "" {
+		*from = formatFromExt(path)
+		if *from == "" {
+			fmt.Fprintln(stderr, "configctl convert: -from is required when reading stdin or an unknown extension")
+			return exitUsage
+		}
+	}
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	opts := []config.EncodeOption{config.WithEnvPrefix(*prefix)}
+	if *types {
+		opts = append(opts, config.WithEnvTypeParse())
+	}
+	out, err := config.Convert(data, config.Format(*from), config.Format(*to), opts...)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	_, _ = stdout.Write(out)
+	return exitOK
+}
+
+func formatFromExt(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".json":
+		return "json"
+	case ".toml":
+		return "toml"
+	case ".env":
+		return "env"
+	}
+	if strings.HasPrefix(filepath.Base(path), ".env") {
+		return "env"
+	}
+	return ""
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shuldan/config"
+)
+
+const usage = `Usage: configctl <command> [flags] [args]
+
+Commands:
+  render            print the merged effective config
+  validate          check the config against a JSON Schema
+  get <key>         print a single value
+  explain <key>     show which source set a value
+  diff <a> <b>      compare two config files
+  convert [file]    convert between yaml, json, toml and env
+
+Common flags:
+  -f, -file path      config file (.yaml, .yml, .json); repeat to merge in order
+  -env-prefix PREFIX  load environment variables with the prefix
+  -profile NAME       also load <file>.<profile>.<ext> over each file
+  -key-file path      decryption key for ENC[...] values
+  -sensitive pattern  mark keys as sensitive; repeatable
+  -show-secrets       print sensitive values instead of [REDACTED]
+
+Run "configctl <command> -h" for command flags.
+`
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+type command func(args []string, stdout, stderr io.Writer) int
+
+var commands = map[string]command{
+	"render":   runRender,
+	"validate": runValidate,
+	"get":      runGet,
+	"explain":  runExplain,
+	"diff":     runDiff,
+	"convert":  runConvert,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "configctl: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+	return cmd(args[1:], stdout, stderr)
+}
+
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+type sourceFlags struct {
+	files       stringList
+	sensitive   stringList
+	envPrefix   string
+	profile     string
+	keyFile     string
+	showSecrets bool
+}
+
+func newFlagSet(name string, stderr io.Writer) (*flag.FlagSet, *sourceFlags) {
+	fs := flag.NewFlagSet("configctl "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	sf := &sourceFlags{}
+	fs.Var(&sf.files, "f", "config file; repeatable")
+	fs.Var(&sf.files, "file", "config file; repeatable")
+	fs.Var(&sf.sensitive, "sensitive", "sensitive key pattern; repeatable")
+	fs.StringVar(&sf.envPrefix, "env-prefix", "", "environment variable prefix")
+	fs.StringVar(&sf.profile, "profile", "", "profile name")
+	fs.StringVar(&sf.keyFile, "key-file", "", "decryption key file")
+	fs.BoolVar(&sf.showSecrets, "show-secrets", false, "print sensitive values")
+	return fs, sf
+}
+
+func parseFlags(fs *flag.FlagSet, args []string, want int) ([]string, bool) {
+	if err := fs.Parse(args); err != nil {
+		return nil, false
+	}
+	rest := fs.Args()
+	if want >= 0 && len(rest) != want {
+		fmt.Fprintf(fs.Output(), "%s: expected %d argument(s), got %d\n", fs.Name(), want, len(rest))
+		return nil, false
+	}
+	return rest, true
+}
+
+func (sf *sourceFlags) options() ([]config.Option, error) {
+	if len(sf.files) == 0 && sf.envPrefix == "" {
+		return nil, errors.New("no config source: use -f or -env-prefix")
+	}
+
+	var opts []config.Option
+	for _, path := range sf.files {
+		loaders, err := fileLoaders(path, sf.profile)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range loaders {
+			opts = append(opts, config.WithLoader(l))
+		}
+	}
+	if sf.envPrefix != "" {
+		opts = append(opts, config.WithLoader(config.FromEnv(sf.envPrefix)))
+	}
+	if sf.keyFile != "" {
+		opts = append(opts, config.WithDecryptionKeyFile(sf.keyFile))
+	}
+	if len(sf.sensitive) > 0 {
+		opts = append(opts, config.WithSensitiveKeys(sf.sensitive...))
+	}
+	return opts, nil
+}
+
+func (sf *sourceFlags) load(extra ...config.Option) (*config.Config, error) {
+	opts, err := sf.options()
+	if err != nil {
+		return nil, err
+	}
+	return config.New(append(opts, extra...)...)
+}
+
+func fileLoaders(path, profile string) ([]config.Loader, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(abs)
+	ext := filepath.Ext(abs)
+
+	paths := []string{abs}
+	if profile != "" {
+		paths = append(paths, strings.TrimSuffix(abs, ext)+"."+profile+ext)
+	}
+
+	loaders := make([]config.Loader, 0, len(paths))
+	for i, p := range paths {
+		switch strings.ToLower(ext) {
+		case ".json":
+			l := config.FromJSON(p).WithBasePath(dir)
+			if i > 0 {
+				l = l.Optional()
+			}
+			loaders = append(loaders, l)
+		case ".yaml", ".yml":
+			l := config.FromYAML(p).WithBasePath(dir)
+			if i > 0 {
+				l = l.Optional()
+			}
+			loaders = append(loaders, l)
+		default:
+			return nil, fmt.Errorf("unsupported file extension %q", ext)
+		}
+	}
+	return loaders, nil
+}
+
+func fail(stderr io.Writer, err error) int {
+	fmt.Fprintf(stderr, "configctl: %v\n", err)
+	return exitFailure
+}
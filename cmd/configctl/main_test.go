@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func runCmd(args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func testFiles(t *testing.T) (base, other string) {
+	t.Helper()
+	dir := t.TempDir()
+	base = writeFile(t, dir, "config.yaml", `
+database:
+  host: '{{ "db" | upper }}'
+  port: 5432
+  password: hunter2
+tags: [a, b]
+`)
+	writeFile(t, dir, "config.prod.yaml", "database:\n  port: 6543\n")
+	other = writeFile(t, dir, "other.json", `{"database": {"host": "DB", "port": 5433}, "debug": true}`)
+	return base, other
+}
+
+func TestRun_Usage(t *testing.T) {
+	t.Parallel()
+	if code, _, stderr := runCmd(); code != exitUsage || !strings.Contains(stderr, "Usage") {
+		t.Errorf("expected usage, got %d %q", code, stderr)
+	}
+	if code, _, stderr := runCmd("nope"); code != exitUsage || !strings.Contains(stderr, `unknown command "nope"`) {
+		t.Errorf("expected unknown command, got %d %q", code, stderr)
+	}
+	if code, stdout, _ := runCmd("help"); code != exitOK || !strings.Contains(stdout, "explain") {
+		t.Errorf("expected help, got %d %q", code, stdout)
+	}
+	if code, _, stderr := runCmd("render"); code != exitFailure || !strings.Contains(stderr, "no config source") {
+		t.Errorf("expected missing source error, got %d %q", code, stderr)
+	}
+	if code, _, _ := runCmd("get", "-f", "x.yaml"); code != exitUsage {
+		t.Errorf("expected usage error for missing key, got %d", code)
+	}
+}
+
+func TestRun_Render(t *testing.T) {
+	t.Parallel()
+	base, _ := testFiles(t)
+	code, stdout, stderr := runCmd("render", "-f", base, "-profile", "prod", "-sensitive", "*.password", "-format", "json")
+	if code != exitOK {
+		t.Fatalf("unexpected exit %d: %s", code, stderr)
+	}
+	for _, want := range []string{`"host": "DB"`, `"port": 6543`, `"password": "[REDACTED]"`} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %s in output:\n%s", want, stdout)
+		}
+	}
+
+	_, stdout, _ = runCmd("render", "-f", base, "-sensitive", "*.password", "-show-secrets", "-sources")
+	if !strings.Contains(stdout, "hunter2") || !strings.Contains(stdout, "# "+base) {
+		t.Errorf("expected secrets and sources in output:\n%s", stdout)
+	}
+}
+
+func TestRun_RenderEnv(t *testing.T) {
+	os.Setenv("CTLTEST_DATABASE__HOST", "from-env")
+	t.Cleanup(func() { os.Unsetenv("CTLTEST_DATABASE__HOST") })
+
+	base, _ := testFiles(t)
+	code, stdout, _ := runCmd("render", "-f", base, "-env-prefix", "CTLTEST_", "-format", "env")
+	if code != exitOK || !strings.Contains(stdout, "CTLTEST_DATABASE__HOST=from-env") {
+		t.Errorf("unexpected output %d:\n%s", code, stdout)
+	}
+}
+
+func TestRun_Validate(t *testing.T) {
+	t.Parallel()
+	base, _ := testFiles(t)
+	schema := writeFile(t, filepath.Dir(base), "schema.json", `{
+  "type": "object",
+  "required": ["database"],
+  "properties": {"database": {"type": "object", "properties": {"port": {"maximum": 6000}}}}
+}`)
+
+	if code, stdout, _ := runCmd("validate", "-f", base, "-schema", schema); code != exitOK || stdout != "ok\n" {
+		t.Errorf("expected ok, got %d %q", code, stdout)
+	}
+	code, stdout, _ := runCmd("validate", "-f", base, "-profile", "prod", "-schema", schema)
+	if code != exitFailure || !strings.Contains(stdout, "database.port: value 6543 is out of range") {
+		t.Errorf("expected violation, got %d %q", code, stdout)
+	}
+
+	typed := writeFile(t, filepath.Dir(base), "typed.json", `{"properties": {"database": {"properties": {"port": {"type": "integer"}}}}}`)
+	os.Setenv("CTLCOERCE_DATABASE__PORT", "5433")
+	t.Cleanup(func() { os.Unsetenv("CTLCOERCE_DATABASE__PORT") })
+	args := []string{"validate", "-f", base, "-env-prefix", "CTLCOERCE_", "-schema", typed}
+	if code, stdout, _ := runCmd(args...); code != exitFailure || !strings.Contains(stdout, "database.port: expected integer, got string") {
+		t.Errorf("expected type violation, got %d %q", code, stdout)
+	}
+	if code, stdout, _ := runCmd(append(args, "-coerce")...); code != exitOK {
+		t.Errorf("expected ok with -coerce, got %d %q", code, stdout)
+	}
+	unsupported := writeFile(t, filepath.Dir(base), "unsupported.json", `{"properties": {"database": {"$ref": "#/$defs/db"}}}`)
+	if code, stdout, stderr := runCmd("validate", "-f", base, "-schema", unsupported); code != exitFailure ||
+		!strings.Contains(stderr, `unsupported keyword "$ref"`) {
+		t.Errorf("expected unsupported keyword error, got %d %q %q", code, stdout, stderr)
+	}
+	if code, _, _ := runCmd("validate", "-f", base); code != exitUsage {
+		t.Errorf("expected usage error without schema, got %d", code)
+	}
+}
+
+func TestRun_Get(t *testing.T) {
+	t.Parallel()
+	base, _ := testFiles(t)
+	cases := map[string]string{
+		"database.port": "5432\n",
+		"tags":          "[\"a\",\"b\"]\n",
+	}
+	for key, want := range cases {
+		if code, stdout, _ := runCmd("get", "-f", base, key); code != exitOK || stdout != want {
+			t.Errorf("get %s: expected %q, got %d %q", key, want, code, stdout)
+		}
+	}
+	if _, stdout, _ := runCmd("get", "-f", base, "-sensitive", "database.password", "database.password"); stdout != "[REDACTED]\n" {
+		t.Errorf("expected redacted value, got %q", stdout)
+	}
+	if code, _, stderr := runCmd("get", "-f", base, "missing"); code != exitFailure || !strings.Contains(stderr, `"missing" not found`) {
+		t.Errorf("expected not found, got %d %q", code, stderr)
+	}
+}
+
+func TestRun_Explain(t *testing.T) {
+	t.Parallel()
+	base, _ := testFiles(t)
+	code, stdout, _ := runCmd("explain", "-f", base, "-profile", "prod", "-sensitive", "*.password", "database")
+	if code != exitOK {
+		t.Fatalf("unexpected exit %d", code)
+	}
+	prod := strings.TrimSuffix(base, ".yaml") + ".prod.yaml"
+	for _, want := range []string{
+		"database.port = 6543\n    source: " + prod,
+		"database.host = DB\n    source: " + base,
+		"database.password = [REDACTED]\n    source: " + base + "\n    sensitive: true",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %q in output:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestRun_Diff(t *testing.T) {
+	t.Parallel()
+	base, other := testFiles(t)
+	code, stdout, _ := runCmd("diff", base, other)
+	if code != exitFailure {
+		t.Errorf("expected differences to exit with %d, got %d", exitFailure, code)
+	}
+	want := "- database.password = hunter2\n~ database.port = 5432 -> 5433\n+ debug = true\n- tags = [\"a\",\"b\"]\n"
+	if stdout != want {
+		t.Errorf("unexpected diff:\n%s", stdout)
+	}
+	if code, stdout, _ := runCmd("diff", base, base); code != exitOK || stdout != "" {
+		t.Errorf("expected no differences, got %d %q", code, stdout)
+	}
+	if _, stdout, _ := runCmd("diff", "-sensitive", "*.password", base, other); !strings.Contains(stdout, "- database.password = [REDACTED]\n") {
+		t.Errorf("expected redacted password, got:\n%s", stdout)
+	}
+
+	dir := filepath.Dir(base)
+	a := writeFile(t, dir, "a.yaml", "port: 8080\n")
+	b := writeFile(t, dir, "b.json", `{"port": "8080"}`)
+	if code, stdout, _ := runCmd("diff", a, b); code != exitFailure || stdout != "~ port = 8080 -> 8080\n" {
+		t.Errorf("expected strict difference, got %d %q", code, stdout)
+	}
+	if code, stdout, _ := runCmd("diff", "-loose", a, b); code != exitOK || stdout != "" {
+		t.Errorf("expected loose equality, got %d %q", code, stdout)
+	}
+
+	la := writeFile(t, dir, "list-a.yaml", "servers:\n  - host: a\n    password: old-secret\n")
+	lb := writeFile(t, dir, "list-b.yaml", "servers:\n  - host: a\n    password: new-secret\n")
+	_, stdout, _ = runCmd("diff", "-sensitive", "*.password", la, lb)
+	if strings.Contains(stdout, "secret") || !strings.Contains(stdout, `"password":"[REDACTED]"`) {
+		t.Errorf("expected passwords in lists to be redacted, got:\n%s", stdout)
+	}
+}
+
+func TestRun_Convert(t *testing.T) {
+	t.Parallel()
+	base, other := testFiles(t)
+
+	code, stdout, stderr := runCmd("convert", "--to", "env", "--prefix", "APP_", base)
+	if code != exitOK {
+		t.Fatalf("unexpected exit %d: %s", code, stderr)
+	}
+	for _, want := range []string{"APP_DATABASE__PORT=5432", "APP_TAGS__0=a\nAPP_TAGS__1=b\n"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %s in output:\n%s", want, stdout)
+		}
+	}
+
+	env := writeFile(t, filepath.Dir(base), "app.env", stdout)
+	code, stdout, stderr = runCmd("convert", "-to", "json", "-prefix", "APP_", "-types", env)
+	if code != exitOK || !strings.Contains(stdout, `"port": 5432`) || !strings.Contains(stdout, "\"tags\": [\n    \"a\",\n    \"b\"\n  ]") {
+		t.Errorf("unexpected output %d %s:\n%s", code, stderr, stdout)
+	}
+
+	camel := writeFile(t, filepath.Dir(base), "camel.yaml", "maxConns: 5\n")
+	if code, _, stderr := runCmd("convert", "-to", "env", camel); code != exitFailure || !strings.Contains(stderr, `read back as "maxconns"`) {
+		t.Errorf("expected unrepresentable key error, got %d %q", code, stderr)
+	}
+
+	if code, stdout, _ := runCmd("convert", "-from", "json", "-to", "toml", other); code != exitOK || !strings.Contains(stdout, "[database]\nhost = \"DB\"") {
+		t.Errorf("unexpected toml output %d:\n%s", code, stdout)
+	}
+	if code, _, _ := runCmd("convert", base); code != exitUsage {
+		t.Errorf("expected usage error without -to, got %d", code)
+	}
+	if code, _, stderr := runCmd("convert", "-to", "json", "-"); code != exitUsage || !strings.Contains(stderr, "-from is required") {
+		t.Errorf("expected missing -from error, got %d %q", code, stderr)
+	}
+}
@@ -31,7 +31,12 @@ type ConfigProvider interface {
 var _ ConfigProvider = (*Config)(nil)
 
 type Config struct {
-	values map[string]any
+	values    map[string]any
+	sensitive *sensitiveKeys
+	sources   sourceMap
+	naming    NameMapper
+	foldCase  bool
+	warn      func(Violation)
 }
 
 func New(opts ...Option) (*Config, error) {
@@ -42,31 +47,81 @@ func New(opts ...Option) (*Config, error) {
 		opt.apply(b)
 	}
 
+	values, sources, err := b.load()
+	if err != nil {
+		return nil, err
+	}
+
+	processed, err := processValue(values, "", b.literal)
+	if err != nil {
+		return nil, fmt.Errorf("config: template rendering failed: %w", err)
+	}
+
+	dec := &decrypter{source: b.keySrc}
+	processed, err = dec.decryptValue(processed, "")
+	if err != nil {
+		return nil, fmt.Errorf("config: decryption failed: %w", err)
+	}
+
+	processedMap, ok := processed.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("config: unexpected processed type %T", processed)
+	}
+
+	b.secrets = append(b.secrets, dec.decrypted...)
+	b.sensitive = append(b.sensitive, b.structSensitiveKeys()...)
+	b.logger.Debug("config: ready", "total_keys", len(processedMap), "sensitive_keys", len(b.secrets))
+
+	cfg := &Config{
+		values:    processedMap,
+		sensitive: newSensitiveKeys(b.sensitive, b.secrets),
+		sources:   sources,
+		naming:    b.naming,
+		foldCase:  b.foldCase,
+		warn:      b.warningHandler(),
+	}
+	if err = cfg.Validate(b.rules...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (b *builder) load() (map[string]any, sourceMap, error) {
 	values := make(map[string]any)
+	sources := make(sourceMap)
+	b.literal = make(map[string]bool)
 
 	for _, loader := range b.loaders {
 		cfg, err := loader.Load()
 		if err != nil {
 			b.logger.Debug("config: loader failed", "error", err)
-			return nil, err
+			return nil, nil, err
 		}
 		b.logger.Debug("config: loader succeeded", "keys", len(cfg))
 		mergeMaps(values, cfg)
-	}
+		sources.record(cfg, loaderName(loader))
 
-	processed, err := processValue(values, "")
-	if err != nil {
-		return nil, fmt.Errorf("config: template rendering failed: %w", err)
+		for key := range b.literal {
+			if _, ok := lookupPath(cfg, key); ok {
+				delete(b.literal, key)
+			}
+		}
+		if src, ok := loader.(sensitiveSource); ok {
+			for _, key := range src.sensitiveKeys() {
+				b.secrets = append(b.secrets, key)
+				b.literal[key] = true
+			}
+		}
 	}
 
-	processedMap, ok := processed.(map[string]any)
-	if !ok {
-		return nil, fmt.Errorf("config: unexpected processed type %T", processed)
+	if err := b.applyMigrations(values); err != nil {
+		return nil, nil, err
+	}
+	if err := b.applyAliases(values, sources); err != nil {
+		return nil, nil, err
 	}
 
-	b.logger.Debug("config: ready", "total_keys", len(processedMap))
-
-	return &Config{values: processedMap}, nil
+	return values, sources, nil
 }
 
 func FromMap(values map[string]any) *Config {
@@ -77,7 +132,16 @@ func (c *Config) WithOverrides(overrides map[string]any) *Config {
 	cp := deepCopyMap(c.values)
 	expanded := expandDotKeys(overrides)
 	mergeMaps(cp, expanded)
-	return &Config{values: cp}
+	sources := c.sources.clone()
+	sources.record(expanded, "overrides")
+	return &Config{
+		values:    cp,
+		sensitive: c.sensitive,
+		sources:   sources,
+		naming:    c.naming,
+		foldCase:  c.foldCase,
+		warn:      c.warn,
+	}
 }
 
 func (c *Config) Has(key string) bool {
@@ -95,13 +159,7 @@ func (c *Config) GetString(key string, defaultVal ...string) string {
 	if !ok {
 		return getFirst(defaultVal)
 	}
-	if v == nil {
-		return ""
-	}
-	if s, ok := v.(string); ok {
-		return s
-	}
-	return fmt.Sprintf("%v", v)
+	return toString(v)
 }
 
 func (c *Config) GetInt(key string, defaultVal ...int) int {
@@ -206,19 +264,8 @@ func (c *Config) GetDuration(key string, defaultVal ...time.Duration) time.Durat
 	if !ok {
 		return getFirst(defaultVal)
 	}
-	switch val := v.(type) {
-	case time.Duration:
-		return val
-	case string:
-		if d, err := time.ParseDuration(val); err == nil {
-			return d
-		}
-	case int:
-		return time.Duration(val) * time.Millisecond
-	case int64:
-		return time.Duration(val) * time.Millisecond
-	case float64:
-		return time.Duration(val * float64(time.Millisecond))
+	if d, ok := toDuration(v); ok {
+		return d
 	}
 	return getFirst(defaultVal)
 }
@@ -346,7 +393,14 @@ func (c *Config) GetSub(key string) (ConfigProvider, bool) {
 		return nil, false
 	}
 	if subMap, ok := sub.(map[string]any); ok {
-		return &Config{values: deepCopyMap(subMap)}, true
+		return &Config{
+			values:    deepCopyMap(subMap),
+			sensitive: c.sensitive.sub(key),
+			sources:   c.sources.sub(key),
+			naming:    c.naming,
+			foldCase:  c.foldCase,
+			warn:      c.warn,
+		}, true
 	}
 	return nil, false
 }
@@ -356,29 +410,5 @@ func (c *Config) All() map[string]any {
 }
 
 func (c *Config) find(path string) (any, bool) {
-	keys := strings.Split(path, ".")
-	var current any = c.values
-
-	for _, k := range keys {
-		if current == nil {
-			return nil, false
-		}
-		switch cur := current.(type) {
-		case map[string]any:
-			next, exists := cur[k]
-			if !exists {
-				return nil, false
-			}
-			current = next
-		case map[any]any:
-			next, exists := cur[k]
-			if !exists {
-				return nil, false
-			}
-			current = next
-		default:
-			return nil, false
-		}
-	}
-	return current, true
+	return lookupPath(c.values, path)
 }
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+func Convert(data []byte, from, to Format, opts ...EncodeOption) ([]byte, error) {
+	o := newEncodeOptions(opts)
+	o.lossless = true
+
+	values, err := decodeValues(data, from, o)
+	if err != nil {
+		return nil, fmt.Errorf("config: convert from %s: %w", from, err)
+	}
+
+	var buf bytes.Buffer
+	if err = encodeValues(&buf, values, to, o); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeValues(data []byte, format Format, o *encodeOptions) (map[string]any, error) {
+	switch format {
+	case FormatYAML:
+		return parseYAML(data)
+	case FormatJSON:
+		return parseJSON(data)
+	case FormatTOML:
+		return parseTOML(data)
+	case FormatEnv:
+		return parseEnvFile(data, o)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func parseEnvFile(data []byte, o *encodeOptions) (map[string]any, error) {
+	var environ []string
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimLeftFunc(strings.TrimSuffix(line, "\r"), unicode.IsSpace)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || key == "" || strings.ContainsFunc(key, unicode.IsSpace) {
+			return nil, errors.Join(ErrParseEnv, fmt.Errorf("line %d: expected KEY=value", i+1))
+		}
+		environ = append(environ, key+"="+value)
+	}
+
+	l := &EnvLoader{prefix: o.envPrefix, autoTypeParse: o.envTypes}
+	values := l.parse(environ)
+	for k, v := range values {
+		values[k] = indexedLists(v)
+	}
+	return values, nil
+}
+
+func indexedLists(v any) any {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) == 0 {
+		return v
+	}
+	for k, item := range m {
+		m[k] = indexedLists(item)
+	}
+	if list, ok := indexedItems(m); ok {
+		return list
+	}
+	return m
+}
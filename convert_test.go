@@ -0,0 +1,296 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const convertYAML = `
+database:
+  host: db.local
+  port: 5432
+  ratio: 0.5
+  tls: true
+motd: hello world
+tags: [a, b]
+servers:
+  - host: s1
+    port: 81
+`
+
+func TestConvert_YAMLToEnv(t *testing.T) {
+	t.Parallel()
+	out, err := Convert([]byte(convertYAML), FormatYAML, FormatEnv, WithEnvPrefix("APP_"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `APP_DATABASE__HOST=db.local
+APP_DATABASE__PORT=5432
+APP_DATABASE__RATIO=0.5
+APP_DATABASE__TLS=true
+APP_MOTD=hello world
+APP_SERVERS__0__HOST=s1
+APP_SERVERS__0__PORT=81
+APP_TAGS__0=a
+APP_TAGS__1=b
+`
+	if string(out) != want {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestConvert_EnvRoundTrip(t *testing.T) {
+	t.Parallel()
+	for name, tc := range map[string]struct {
+		data   string
+		format Format
+	}{
+		"yaml": {convertYAML, FormatYAML},
+		"json": {`{"servers": [{"host": "a", "port": 1}, {"host": "b", "port": 2.5}], "name": "svc"}`, FormatJSON},
+	} {
+		env, err := Convert([]byte(tc.data), tc.format, FormatEnv, WithEnvPrefix("APP_"), WithEnvTypeParse())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		back, err := Convert(env, FormatEnv, FormatJSON, WithEnvPrefix("APP_"), WithEnvTypeParse())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		want, _ := Convert([]byte(tc.data), tc.format, FormatJSON)
+		if string(back) != string(want) {
+			t.Errorf("%s: round trip mismatch:\n%s\nwant:\n%s", name, back, want)
+		}
+	}
+}
+
+func TestConvert_EnvMatchesEnvLoader(t *testing.T) {
+	env, err := Convert([]byte(convertYAML), FormatYAML, FormatEnv, WithEnvPrefix("CONVTEST_"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(env)), "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		os.Setenv(key, value)
+		t.Cleanup(func() { os.Unsetenv(key) })
+	}
+
+	cfg, err := New(WithLoader(FromEnv("CONVTEST_")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var target struct {
+		Database struct {
+			Host string  `cfg:"host"`
+			Port int     `cfg:"port"`
+			TLS  bool    `cfg:"tls"`
+			Rate float64 `cfg:"ratio"`
+		} `cfg:"database"`
+		Motd    string           `cfg:"motd"`
+		Tags    []string         `cfg:"tags"`
+		Servers []upstreamTarget `cfg:"servers"`
+	}
+	if err = cfg.Unmarshal("", &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Database.Port != 5432 || !target.Database.TLS || target.Motd != "hello world" ||
+		!reflect.DeepEqual(target.Tags, []string{"a", "b"}) || len(target.Servers) != 1 || target.Servers[0].Port != 81 {
+		t.Errorf("unexpected target: %+v", target)
+	}
+}
+
+func TestConvert_EnvUnrepresentable(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		data  string
+		typed bool
+		want  string
+	}{
+		"camel case key":     {data: "maxConns: 5", want: `key would be read back as "maxconns"`},
+		"dash in key":        {data: "my-key: x", want: "APP_MY-KEY is not a valid environment variable name"},
+		"double underscore":  {data: "a__b: x", want: `key would be read back as "a.b"`},
+		"null":               {data: "a: null", want: "null value has no env representation"},
+		"empty list":         {data: "a: []", want: "array value has no env representation"},
+		"empty map":          {data: "a: {}", want: "object value has no env representation"},
+		"line break":         {data: "a: \"one\\ntwo\"", want: "line break"},
+		"numeric string":     {data: `a: "007"`, typed: true, want: "string value would be read back as integer"},
+		"boolean string":     {data: `a: "TRUE"`, typed: true, want: "string value would be read back as boolean"},
+		"untyped is lenient": {data: `a: "007"`},
+		"index keyed map":    {data: `m: {"0": a, "1": b}`, want: `"m": map with keys 0..n-1 would be read back as a list`},
+		"nested index map":   {data: `l: [{"0": a}]`, want: `"l.0": map with keys 0..n-1`},
+		"sparse index map":   {data: `m: {"0": a, "2": b}`},
+	}
+	for name, tc := range cases {
+		opts := []EncodeOption{WithEnvPrefix("APP_")}
+		if tc.typed {
+			opts = append(opts, WithEnvTypeParse())
+		}
+		_, err := Convert([]byte(tc.data), FormatYAML, FormatEnv, opts...)
+		if tc.want == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected %q, got %v", name, tc.want, err)
+		}
+	}
+}
+
+func TestConvert_JSONIntegers(t *testing.T) {
+	t.Parallel()
+	data := []byte(`{"port": 8080, "ratio": 1.0, "big": 12345678901234567890, "neg": -5, "list": [1, 2.5]}`)
+	cases := map[Format][]string{
+		FormatYAML: {"port: 8080\n", "ratio: 1.0\n", "big: 12345678901234567890\n", "neg: -5\n", "- 1\n"},
+		FormatJSON: {`"port": 8080,`, `"big": 12345678901234567890,`},
+		FormatEnv:  {"PORT=8080\n", "BIG=12345678901234567890\n", "LIST__0=1\n"},
+	}
+	for to, wants := range cases {
+		out, err := Convert(data, FormatJSON, to)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", to, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(out), want) {
+				t.Errorf("%s: expected %q in:\n%s", to, want, out)
+			}
+		}
+	}
+
+	toml, err := Convert([]byte(`{"port": 8080, "ratio": 1.0, "list": [1, 2.5]}`), FormatJSON, FormatTOML)
+	if err != nil || string(toml) != "list = [1, 2.5]\nport = 8080\nratio = 1.0\n" {
+		t.Errorf("unexpected TOML %q: %v", toml, err)
+	}
+	if _, err = Convert(data, FormatJSON, FormatTOML); err == nil || !strings.Contains(err.Error(), "out of range for TOML") {
+		t.Errorf("expected out of range error, got %v", err)
+	}
+
+	if _, err = Convert([]byte(`{"a": 1} x`), FormatJSON, FormatYAML); !errors.Is(err, ErrParseJSON) {
+		t.Errorf("expected trailing data error, got %v", err)
+	}
+}
+
+func TestConvert_TOMLAndJSON(t *testing.T) {
+	t.Parallel()
+	toml, err := Convert([]byte(convertYAML), FormatYAML, FormatTOML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	yml, err := Convert(toml, FormatTOML, FormatYAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := parseYAML(yml)
+	want, _ := parseYAML([]byte(convertYAML))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch:\n%v\n%v", got, want)
+	}
+}
+
+func TestConvert_EnvFileSyntax(t *testing.T) {
+	t.Parallel()
+	data := "# generated\r\n" +
+		"APP_NAME='single # quoted'\r\n" +
+		"  APP_HOST=db.local # not a comment\n" +
+		"APP_EMPTY=\n" +
+		"APP_EQ=a=b\n" +
+		"OTHER=ignored\n"
+	m, err := decodeValues([]byte(data), FormatEnv, &encodeOptions{envPrefix: "APP_"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]any{"name": "'single # quoted'", "host": "db.local # not a comment", "empty": "", "eq": "a=b"}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("unexpected values: %v", m)
+	}
+}
+
+func TestConvert_Errors(t *testing.T) {
+	t.Parallel()
+	if _, err := Convert([]byte("a"), FormatEnv, FormatYAML); !errors.Is(err, ErrParseEnv) {
+		t.Errorf("expected ErrParseEnv, got %v", err)
+	}
+	if _, err := Convert([]byte("A B=1"), FormatEnv, FormatYAML); !errors.Is(err, ErrParseEnv) {
+		t.Errorf("expected ErrParseEnv, got %v", err)
+	}
+	if _, err := Convert([]byte("{"), FormatJSON, FormatYAML); !errors.Is(err, ErrParseJSON) {
+		t.Errorf("expected ErrParseJSON, got %v", err)
+	}
+	if _, err := Convert([]byte("a: 1"), "ini", FormatYAML); err == nil || !strings.Contains(err.Error(), `unsupported format "ini"`) {
+		t.Errorf("expected unsupported format, got %v", err)
+	}
+	if _, err := Convert([]byte("a: 1"), FormatYAML, "ini"); err == nil {
+		t.Error("expected unsupported output format error")
+	}
+	if _, err := Convert([]byte(`password: "hunter2\nx"`), FormatYAML, FormatEnv); err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("expected an error without the value, got %v", err)
+	}
+}
+
+func TestParseEnvFile_IndexedLists(t *testing.T) {
+	t.Parallel()
+	data := "APP_TAGS__1=b\nAPP_TAGS__0=a\nAPP_SERVERS__0__HOST=s1\nAPP_GAPS__0=x\nAPP_GAPS__2=z\nAPP_CODES__01=x\n"
+	got, err := parseEnvFile([]byte(data), &encodeOptions{envPrefix: "APP_"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]any{
+		"tags":    []any{"a", "b"},
+		"servers": []any{map[string]any{"host": "s1"}},
+		"gaps":    map[string]any{"0": "x", "2": "z"},
+		"codes":   map[string]any{"01": "x"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected values: %v", got)
+	}
+
+	l := &EnvLoader{prefix: "APP_"}
+	if loaded := l.parse([]string{"APP_TAGS__0=a"}); !reflect.DeepEqual(loaded, map[string]any{"tags": map[string]any{"0": "a"}}) {
+		t.Errorf("expected EnvLoader to keep index keys, got %v", loaded)
+	}
+}
+
+func TestNew_EnvIndexesMergeIntoLists(t *testing.T) {
+	t.Parallel()
+	for key, value := range map[string]string{
+		"IDXMERGE_SERVERS__0__HOST": "c",
+		"IDXMERGE_LIMITS__0":        "5",
+		"IDXMERGE_PORTS__3":         "9",
+	} {
+		os.Setenv(key, value)
+		t.Cleanup(func() { os.Unsetenv(key) })
+	}
+
+	base := &staticLoader{data: map[string]any{
+		"servers": []any{
+			map[string]any{"host": "a", "port": 1},
+			map[string]any{"host": "b", "port": 2},
+		},
+		"limits": map[string]any{"0": "1", "9": "2"},
+		"ports":  []any{"1"},
+	}}
+	cfg, err := New(WithLoader(base), WithLoader(FromEnv("IDXMERGE_")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []any{
+		map[string]any{"host": "c", "port": 1},
+		map[string]any{"host": "b", "port": 2},
+	}
+	if !reflect.DeepEqual(cfg.Get("servers"), want) {
+		t.Errorf("unexpected servers: %v", cfg.Get("servers"))
+	}
+	if !reflect.DeepEqual(cfg.Get("limits"), map[string]any{"0": "5", "9": "2"}) {
+		t.Errorf("unexpected limits: %v", cfg.Get("limits"))
+	}
+	if !reflect.DeepEqual(cfg.Get("ports"), map[string]any{"3": "9"}) {
+		t.Errorf("expected out-of-range index to replace the list, got %v", cfg.Get("ports"))
+	}
+	if cfg.Source("servers[0].host") != "env:IDXMERGE_" || cfg.Source("servers[1].port") == "env:IDXMERGE_" {
+		t.Errorf("unexpected sources: %q, %q", cfg.Source("servers[0].host"), cfg.Source("servers[1].port"))
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sync"
+)
+
+type decodeFunc func(val any) (reflect.Value, error)
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+	decodersMu sync.RWMutex
+	decoders   = make(map[reflect.Type]decodeFunc)
+)
+
+func init() {
+	RegisterDecoder(decodeURL)
+}
+
+func RegisterDecoder[T any](fn func(val any) (T, error)) {
+	t := reflect.TypeFor[T]()
+
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+
+	decoders[t] = func(val any) (reflect.Value, error) {
+		out, err := fn(val)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&out).Elem(), nil
+	}
+}
+
+func lookupDecoder(t reflect.Type) (decodeFunc, bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+
+	fn, ok := decoders[t]
+	return fn, ok
+}
+
+func hasCustomDecoder(t reflect.Type) bool {
+	if _, ok := lookupDecoder(t); ok {
+		return true
+	}
+	pt := reflect.PointerTo(t)
+	return pt.Implements(configDecoderType) || pt.Implements(textUnmarshalerType) || pt.Implements(jsonUnmarshalerType)
+}
+
+func convertWithUnmarshaler(val any, t reflect.Type) (reflect.Value, bool, error) {
+	pt := reflect.PointerTo(t)
+	isText := pt.Implements(textUnmarshalerType)
+	isJSON := pt.Implements(jsonUnmarshalerType)
+
+	if !isText && !isJSON {
+		return reflect.Value{}, false, nil
+	}
+
+	ptr := reflect.New(t)
+
+	if isText && isScalar(val) {
+		text := fmt.Sprintf("%v", val)
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+			return reflect.Value{}, true, fmt.Errorf("cannot parse %q as %s: %w", text, t, err)
+		}
+		return ptr.Elem(), true, nil
+	}
+
+	if isJSON {
+		data, err := json.Marshal(val)
+		if err != nil {
+			return reflect.Value{}, true, fmt.Errorf("cannot encode %T for %s: %w", val, t, err)
+		}
+		if err = ptr.Interface().(json.Unmarshaler).UnmarshalJSON(data); err != nil {
+			return reflect.Value{}, true, fmt.Errorf("cannot decode %s: %w", t, err)
+		}
+		return ptr.Elem(), true, nil
+	}
+
+	return reflect.Value{}, true, fmt.Errorf("cannot convert %T to %s", val, t)
+}
+
+func isScalar(val any) bool {
+	switch val.(type) {
+	case map[string]any, []any, []string:
+		return false
+	default:
+		return true
+	}
+}
+
+func decodeURL(val any) (url.URL, error) {
+	s, ok := val.(string)
+	if !ok {
+		return url.URL{}, fmt.Errorf("cannot convert %T to url.URL", val)
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return url.URL{}, fmt.Errorf("cannot parse URL %q: %w", s, err)
+	}
+	return *u, nil
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testColor int
+
+func (c *testColor) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "red":
+		*c = 1
+	case "green":
+		*c = 2
+	default:
+		return fmt.Errorf("unknown color %q", text)
+	}
+	return nil
+}
+
+type testPair struct {
+	a, b string
+}
+
+func (p *testPair) UnmarshalJSON(data []byte) error {
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	p.a, p.b = raw["a"], raw["b"]
+	return nil
+}
+
+type testCelsius float64
+
+type decoderTarget struct {
+	IP      net.IP      `cfg:"ip"`
+	IPs     []net.IP    `cfg:"ips"`
+	URL     url.URL     `cfg:"url"`
+	URLPtr  *url.URL    `cfg:"url_ptr"`
+	Level   slog.Level  `cfg:"level" default:"warn"`
+	Big     *big.Int    `cfg:"big"`
+	Color   testColor   `cfg:"color"`
+	Pair    testPair    `cfg:"pair"`
+	Temp    testCelsius `cfg:"temp"`
+	Missing testColor   `cfg:"missing" default:"green"`
+}
+
+func init() {
+	RegisterDecoder(func(val any) (testCelsius, error) {
+		s, ok := val.(string)
+		if !ok {
+			return 0, fmt.Errorf("expected string, got %T", val)
+		}
+		var f float64
+		if _, err := fmt.Sscanf(s, "%fC", &f); err != nil {
+			return 0, err
+		}
+		return testCelsius(f), nil
+	})
+}
+
+func TestUnmarshal_CustomDecoders(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"ip":      "10.0.0.1",
+		"ips":     []any{"127.0.0.1", "::1"},
+		"url":     "https://example.com/path?q=1",
+		"url_ptr": "http://localhost:8080",
+		"big":     "123456789012345678901234567890",
+		"color":   "Red",
+		"pair":    map[string]any{"a": "x", "b": "y"},
+		"temp":    "21.5C",
+	})
+	var target decoderTarget
+	if err := cfg.Unmarshal("", &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !target.IP.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("unexpected ip: %v", target.IP)
+	}
+	if len(target.IPs) != 2 || !target.IPs[1].Equal(net.IPv6loopback) {
+		t.Errorf("unexpected ips: %v", target.IPs)
+	}
+	if target.URL.Host != "example.com" || target.URL.Query().Get("q") != "1" {
+		t.Errorf("unexpected url: %v", target.URL)
+	}
+	if target.URLPtr == nil || target.URLPtr.Port() != "8080" {
+		t.Errorf("unexpected url pointer: %v", target.URLPtr)
+	}
+	if target.Level != slog.LevelWarn {
+		t.Errorf("expected default warn level, got %v", target.Level)
+	}
+	if target.Big == nil || target.Big.String() != "123456789012345678901234567890" {
+		t.Errorf("unexpected big: %v", target.Big)
+	}
+	if target.Color != 1 || target.Missing != 2 {
+		t.Errorf("unexpected colors: %v %v", target.Color, target.Missing)
+	}
+	if target.Pair.a != "x" || target.Pair.b != "y" {
+		t.Errorf("unexpected pair: %+v", target.Pair)
+	}
+	if target.Temp != 21.5 {
+		t.Errorf("unexpected temp: %v", target.Temp)
+	}
+}
+
+func TestUnmarshal_TextUnmarshalerNumber(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{"n": 42})
+	var target struct {
+		N big.Int `cfg:"n"`
+	}
+	if err := cfg.Unmarshal("", &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.N.Int64() != 42 {
+		t.Errorf("expected 42, got %v", target.N.String())
+	}
+}
+
+func TestUnmarshal_CustomDecoderErrors(t *testing.T) {
+	t.Parallel()
+	cases := map[string]map[string]any{
+		"text":     {"color": "purple"},
+		"text_map": {"color": map[string]any{"a": 1}},
+		"json":     {"pair": "not an object"},
+		"registry": {"temp": 42},
+		"url":      {"url": ":bad"},
+		"url_type": {"url": 1},
+	}
+	for name, values := range cases {
+		var target decoderTarget
+		if err := newTestConfig(values).Unmarshal("", &target); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestRegisterDecoder_Override(t *testing.T) {
+	t.Parallel()
+	type token string
+	RegisterDecoder(func(val any) (token, error) {
+		if val == "" {
+			return "", errors.New("empty token")
+		}
+		return token(fmt.Sprintf("tok-%v", val)), nil
+	})
+	cfg := newTestConfig(map[string]any{"t": 7})
+	var target struct {
+		T token `cfg:"t"`
+	}
+	if err := cfg.Unmarshal("", &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.T != "tok-7" {
+		t.Errorf("expected tok-7, got %q", target.T)
+	}
+}
+
+func TestHasCustomDecoder(t *testing.T) {
+	t.Parallel()
+	if !hasCustomDecoder(reflect.TypeFor[url.URL]()) || !hasCustomDecoder(reflect.TypeFor[big.Int]()) {
+		t.Error("expected url.URL and big.Int to have decoders")
+	}
+	if hasCustomDecoder(reflect.TypeFor[basicTarget]()) {
+		t.Error("expected plain struct to have no decoder")
+	}
+}
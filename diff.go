@@ -0,0 +1,210 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+type Change struct {
+	Key       string
+	Kind      ChangeKind
+	Old       any
+	New       any
+	Sensitive bool
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s = %s", c.Key, diffValue(c.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s = %s", c.Key, diffValue(c.Old))
+	default:
+		return fmt.Sprintf("~ %s = %s -> %s", c.Key, diffValue(c.Old), diffValue(c.New))
+	}
+}
+
+type DiffOption interface {
+	applyDiff(o *diffOptions)
+}
+
+type diffOptions struct {
+	loose  bool
+	reveal bool
+}
+
+type diffOptionFunc func(*diffOptions)
+
+func (f diffOptionFunc) applyDiff(o *diffOptions) { f(o) }
+
+func WithLooseTypes() DiffOption {
+	return diffOptionFunc(func(o *diffOptions) {
+		o.loose = true
+	})
+}
+
+func WithRevealedSecrets() DiffOption {
+	return diffOptionFunc(func(o *diffOptions) {
+		o.reveal = true
+	})
+}
+
+type sensitivityReporter interface {
+	IsSensitive(key string) bool
+}
+
+func Diff(a, b ConfigProvider, opts ...DiffOption) []Change {
+	o := &diffOptions{}
+	for _, opt := range opts {
+		opt.applyDiff(o)
+	}
+
+	before, after := diffLeaves(a), diffLeaves(b)
+
+	keys := slices.Collect(maps.Keys(before))
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	changes := make([]Change, 0, len(keys))
+	for _, key := range keys {
+		oldVal, inOld := before[key]
+		newVal, inNew := after[key]
+
+		c := Change{Key: key, Old: oldVal, New: newVal}
+		switch {
+		case !inNew:
+			c.Kind = ChangeRemoved
+		case !inOld:
+			c.Kind = ChangeAdded
+		case diffEqual(oldVal, newVal, o.loose):
+			continue
+		default:
+			c.Kind = ChangeModified
+		}
+
+		redactedOld, oldSensitive := redactIn(c.Old, key, a, b)
+		redactedNew, newSensitive := redactIn(c.New, key, a, b)
+		c.Sensitive = oldSensitive || newSensitive
+		if c.Sensitive && !o.reveal {
+			if inOld {
+				c.Old = redactedOld
+			}
+			if inNew {
+				c.New = redactedNew
+			}
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+func diffLeaves(p ConfigProvider) map[string]any {
+	leaves := make(map[string]any)
+	if values := p.All(); len(values) > 0 {
+		flattenValue("", values, leaves)
+	}
+	return leaves
+}
+
+func redactIn(v any, key string, providers ...ConfigProvider) (any, bool) {
+	sensitive := false
+	for _, p := range providers {
+		s, ok := p.(sensitivityReporter)
+		if !ok {
+			continue
+		}
+		var redacted bool
+		v, redacted = redactSensitive(s, v, key)
+		sensitive = sensitive || redacted
+	}
+	return v, sensitive
+}
+
+func redactSensitive(s sensitivityReporter, v any, key string) (any, bool) {
+	if s.IsSensitive(key) {
+		return redactedValue, true
+	}
+
+	sensitive := false
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			var redacted bool
+			out[k], redacted = redactSensitive(s, item, joinKey(key, k))
+			sensitive = sensitive || redacted
+		}
+		return out, sensitive
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			var redacted bool
+			out[i], redacted = redactSensitive(s, item, key)
+			sensitive = sensitive || redacted
+		}
+		return out, sensitive
+	default:
+		return v, false
+	}
+}
+
+func diffEqual(a, b any, loose bool) bool {
+	if am, ok := a.(map[string]any); ok {
+		bm, ok := b.(map[string]any)
+		return ok && maps.EqualFunc(am, bm, func(x, y any) bool { return diffEqual(x, y, loose) })
+	}
+	if as, ok := a.([]any); ok {
+		bs, ok := b.([]any)
+		return ok && slices.EqualFunc(as, bs, func(x, y any) bool { return diffEqual(x, y, loose) })
+	}
+
+	if isNumber(a) && isNumber(b) {
+		af, _ := toFloat64(a)
+		bf, _ := toFloat64(b)
+		return af == bf
+	}
+	if loose && a != nil && b != nil {
+		return looseEqual(a, b) && looseEqual(b, a)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func isNumber(v any) bool {
+	switch v.(type) {
+	case int, int64, uint64, float64:
+		return true
+	}
+	return false
+}
+
+func diffValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case nil:
+		return "null"
+	case map[string]any, []any:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	a := newTestConfig(map[string]any{
+		"db":    map[string]any{"host": "a", "port": 5432, "pool": map[string]any{}},
+		"tags":  []any{"x", "y"},
+		"debug": true,
+		"ratio": 0.5,
+	})
+	b := newTestConfig(map[string]any{
+		"db":    map[string]any{"host": "b", "port": uint64(5432), "pool": map[string]any{"size": 10}},
+		"tags":  []any{"x", "y"},
+		"ratio": float64(0.5),
+		"name":  "svc",
+	})
+
+	got := Diff(a, b)
+	want := []Change{
+		{Key: "db.host", Kind: ChangeModified, Old: "a", New: "b"},
+		{Key: "db.pool", Kind: ChangeRemoved, Old: map[string]any{}},
+		{Key: "db.pool.size", Kind: ChangeAdded, New: 10},
+		{Key: "debug", Kind: ChangeRemoved, Old: true},
+		{Key: "name", Kind: ChangeAdded, New: "svc"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected changes:\n%v\nwant:\n%v", got, want)
+	}
+
+	if changes := Diff(a, a); len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+	if changes := Diff(newTestConfig(map[string]any{}), newTestConfig(map[string]any{"a": 1})); len(changes) != 1 || changes[0].Key != "a" {
+		t.Errorf("unexpected changes from empty config: %v", changes)
+	}
+}
+
+func TestDiff_LooseTypes(t *testing.T) {
+	t.Parallel()
+	a := newTestConfig(map[string]any{"port": 8080, "debug": true, "list": []any{1, "2"}, "empty": nil})
+	b := newTestConfig(map[string]any{"port": "8080", "debug": "true", "list": []any{"1", 2}, "empty": ""})
+
+	if changes := Diff(a, b); len(changes) != 4 {
+		t.Errorf("expected strict comparison to report 4 changes, got %v", changes)
+	}
+	changes := Diff(a, b, WithLooseTypes())
+	if len(changes) != 1 || changes[0].Key != "empty" {
+		t.Errorf("expected only the nil value to differ, got %v", changes)
+	}
+}
+
+func TestDiff_Redaction(t *testing.T) {
+	t.Parallel()
+	a := newTestConfig(map[string]any{"db": map[string]any{"password": "old", "host": "h"}})
+	a.sensitive = newSensitiveKeys([]string{"*.password"}, nil)
+	b := newTestConfig(map[string]any{"db": map[string]any{"password": "new", "host": "h"}, "token": "t"})
+	b.sensitive = newSensitiveKeys(nil, []string{"token"})
+
+	got := Diff(a, b)
+	want := []Change{
+		{Key: "db.password", Kind: ChangeModified, Old: redactedValue, New: redactedValue, Sensitive: true},
+		{Key: "token", Kind: ChangeAdded, New: redactedValue, Sensitive: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected changes: %v", got)
+	}
+
+	got = Diff(a, b, WithRevealedSecrets())
+	if got[0].Old != "old" || got[0].New != "new" || !got[0].Sensitive {
+		t.Errorf("expected revealed values, got %v", got[0])
+	}
+}
+
+func TestDiff_RedactionInLists(t *testing.T) {
+	t.Parallel()
+	a := newTestConfig(map[string]any{"servers": []any{
+		map[string]any{"host": "a", "password": "old"},
+		map[string]any{"host": "b", "tls": map[string]any{"key": "k1"}},
+	}})
+	a.sensitive = newSensitiveKeys([]string{"*.password", "servers.tls"}, nil)
+	b := newTestConfig(map[string]any{"servers": []any{
+		map[string]any{"host": "a", "password": "new"},
+		map[string]any{"host": "c", "tls": map[string]any{"key": "k2"}},
+	}})
+	b.sensitive = a.sensitive
+
+	got := Diff(a, b)
+	if len(got) != 1 || !got[0].Sensitive {
+		t.Fatalf("expected one sensitive change, got %v", got)
+	}
+	want := `~ servers = [{"host":"a","password":"[REDACTED]"},{"host":"b","tls":"[REDACTED]"}] -> ` +
+		`[{"host":"a","password":"[REDACTED]"},{"host":"c","tls":"[REDACTED]"}]`
+	if s := got[0].String(); s != want {
+		t.Errorf("unexpected change:\n%s\nwant:\n%s", s, want)
+	}
+	if strings.Contains(fmt.Sprint(got[0].Old, got[0].New), "old") || strings.Contains(fmt.Sprint(got[0].New), "k2") {
+		t.Errorf("secret leaked: %v", got[0])
+	}
+
+	revealed := Diff(a, b, WithRevealedSecrets())
+	if !strings.Contains(revealed[0].String(), `"password":"new"`) || !revealed[0].Sensitive {
+		t.Errorf("expected revealed values, got %v", revealed[0])
+	}
+
+	plain := newTestConfig(map[string]any{"servers": []any{map[string]any{"host": "a"}}})
+	other := newTestConfig(map[string]any{"servers": []any{map[string]any{"host": "b"}}})
+	if changes := Diff(plain, other); changes[0].Sensitive {
+		t.Errorf("expected non-sensitive change, got %v", changes[0])
+	}
+}
+
+func TestChange_String(t *testing.T) {
+	t.Parallel()
+	cases := map[string]Change{
+		"+ a = 1":                        {Key: "a", Kind: ChangeAdded, New: 1},
+		"- b = null":                     {Key: "b", Kind: ChangeRemoved},
+		`~ c = ["x"] -> {"k":1}`:         {Key: "c", Kind: ChangeModified, Old: []any{"x"}, New: map[string]any{"k": 1}},
+		"~ d = [REDACTED] -> [REDACTED]": {Key: "d", Kind: ChangeModified, Old: redactedValue, New: redactedValue},
+	}
+	for want, c := range cases {
+		if got := c.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	}
+}
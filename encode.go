@@ -0,0 +1,290 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+var yamlPathKey = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatTOML Format = "toml"
+	FormatEnv  Format = "env"
+)
+
+type EncodeOption interface {
+	applyEncode(o *encodeOptions)
+}
+
+type encodeOptions struct {
+	naming     NameMapper
+	envPrefix  string
+	redact     bool
+	provenance bool
+	envTypes   bool
+	lossless   bool
+	source     func(key string) string
+}
+
+type encodeOptionFunc func(*encodeOptions)
+
+func (f encodeOptionFunc) applyEncode(o *encodeOptions) { f(o) }
+
+func newEncodeOptions(opts []EncodeOption) *encodeOptions {
+	o := &encodeOptions{}
+	for _, opt := range opts {
+		opt.applyEncode(o)
+	}
+	return o
+}
+
+func WithEnvPrefix(prefix string) EncodeOption {
+	return encodeOptionFunc(func(o *encodeOptions) {
+		o.envPrefix = prefix
+	})
+}
+
+func WithNameMapper(m NameMapper) EncodeOption {
+	return encodeOptionFunc(func(o *encodeOptions) {
+		o.naming = m
+	})
+}
+
+func WithEnvTypeParse() EncodeOption {
+	return encodeOptionFunc(func(o *encodeOptions) {
+		o.envTypes = true
+	})
+}
+
+func WithRedaction() EncodeOption {
+	return encodeOptionFunc(func(o *encodeOptions) {
+		o.redact = true
+	})
+}
+
+func WithProvenance() EncodeOption {
+	return encodeOptionFunc(func(o *encodeOptions) {
+		o.provenance = true
+	})
+}
+
+func (c *Config) Encode(w io.Writer, format Format, opts ...EncodeOption) error {
+	o := newEncodeOptions(opts)
+
+	values := c.values
+	if o.redact {
+		values = c.Redacted()
+	}
+	if o.provenance {
+		o.source = c.Source
+	}
+
+	return encodeValues(w, values, format, o)
+}
+
+func MarshalFormat(v any, format Format, opts ...EncodeOption) ([]byte, error) {
+	m, err := Marshal(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = encodeValues(&buf, m, format, newEncodeOptions(opts)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeValues(w io.Writer, m map[string]any, format Format, o *encodeOptions) error {
+	switch format {
+	case FormatYAML:
+		data, err := yaml.MarshalWithOptions(m, yaml.WithComment(o.yamlComments(m)))
+		if err != nil {
+			return fmt.Errorf("config: encode yaml: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+
+	case FormatJSON:
+		if o.source != nil {
+			return fmt.Errorf("config: provenance comments are not supported by %s", format)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(m); err != nil {
+			return fmt.Errorf("config: encode json: %w", err)
+		}
+		return nil
+
+	case FormatTOML:
+		return encodeTOML(w, m, o.comment)
+
+	case FormatEnv:
+		return encodeEnv(w, m, o)
+
+	default:
+		return fmt.Errorf("config: unsupported format %q", format)
+	}
+}
+
+func (o *encodeOptions) comment(key string) string {
+	if o.source == nil {
+		return ""
+	}
+	return o.source(key)
+}
+
+func (o *encodeOptions) yamlComments(m map[string]any) yaml.CommentMap {
+	comments := make(yaml.CommentMap)
+	if o.source == nil {
+		return comments
+	}
+
+	leaves := make(map[string]any)
+	flattenValue("", m, leaves)
+
+	for key := range leaves {
+		if !yamlPathKey.MatchString(key) {
+			continue
+		}
+		if src := o.source(key); src != "" {
+			comments["$."+key] = []*yaml.Comment{yaml.LineComment(" " + src)}
+		}
+	}
+	return comments
+}
+
+func encodeEnv(w io.Writer, m map[string]any, o *encodeOptions) error {
+	if o.lossless {
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			if key, ok := indexedMapKey(k, m[k]); ok {
+				return fmt.Errorf("config: encode env %q: map with keys 0..n-1 would be read back as a list", key)
+			}
+		}
+	}
+
+	leaves := make(map[string]any)
+	flattenEnv("", m, leaves)
+
+	for _, key := range slices.Sorted(maps.Keys(leaves)) {
+		v := leaves[key]
+		name := configKeyToEnv(key, o.envPrefix)
+		if err := o.checkEnv(key, name, v); err != nil {
+			return fmt.Errorf("config: encode env %q: %w", key, err)
+		}
+		if v == nil || isEmptyCollection(v) {
+			continue
+		}
+		if src := o.comment(key); src != "" {
+			if _, err := fmt.Fprintf(w, "# %s\n", src); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", name, toString(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func flattenEnv(prefix string, v any, out map[string]any) {
+	switch val := v.(type) {
+	case map[string]any:
+		if len(val) == 0 {
+			out[prefix] = val
+		}
+		for k, item := range val {
+			flattenEnv(joinKey(prefix, k), item, out)
+		}
+	case []any:
+		if len(val) == 0 {
+			out[prefix] = val
+		}
+		for i, item := range val {
+			flattenEnv(joinKey(prefix, strconv.Itoa(i)), item, out)
+		}
+	default:
+		out[prefix] = v
+	}
+}
+
+func indexedMapKey(key string, v any) (string, bool) {
+	switch val := v.(type) {
+	case map[string]any:
+		if _, ok := indexedItems(val); ok {
+			return key, true
+		}
+		for _, k := range slices.Sorted(maps.Keys(val)) {
+			if found, ok := indexedMapKey(joinKey(key, k), val[k]); ok {
+				return found, true
+			}
+		}
+	case []any:
+		for i, item := range val {
+			if found, ok := indexedMapKey(joinKey(key, strconv.Itoa(i)), item); ok {
+				return found, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (o *encodeOptions) checkEnv(key, name string, v any) error {
+	if !envNamePattern.MatchString(name) {
+		return fmt.Errorf("%s is not a valid environment variable name", name)
+	}
+	if s, ok := v.(string); ok && strings.ContainsAny(s, "\r\n") {
+		return errors.New("value contains a line break, which env files cannot hold")
+	}
+	if !o.lossless {
+		return nil
+	}
+
+	if back := envToConfigKey(name, o.envPrefix); back != key {
+		return fmt.Errorf("key would be read back as %q", back)
+	}
+	if v == nil || isEmptyCollection(v) {
+		return fmt.Errorf("%s value has no env representation", schemaTypeOf(v))
+	}
+	if o.envTypes {
+		if back := autoParseString(toString(v)); !sameEnvValue(back, v) {
+			return fmt.Errorf("%s value would be read back as %s", schemaTypeOf(v), schemaTypeOf(back))
+		}
+	}
+	return nil
+}
+
+func sameEnvValue(parsed, v any) bool {
+	if isNumber(parsed) && isNumber(v) {
+		return diffEqual(parsed, v, false)
+	}
+	return reflect.DeepEqual(parsed, v)
+}
+
+func isEmptyCollection(v any) bool {
+	switch val := v.(type) {
+	case map[string]any:
+		return len(val) == 0
+	case []any:
+		return len(val) == 0
+	default:
+		return false
+	}
+}
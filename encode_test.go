@@ -0,0 +1,192 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+type encodeSample struct {
+	Server struct {
+		Host string   `cfg:"host"`
+		Port int      `cfg:"port"`
+		Tags []string `cfg:"tags"`
+	} `cfg:"server"`
+	Motd    string           `cfg:"motd"`
+	Servers []upstreamTarget `cfg:"servers"`
+}
+
+func newEncodeSample() encodeSample {
+	var v encodeSample
+	v.Server.Host = "localhost"
+	v.Server.Port = 8080
+	v.Server.Tags = []string{"a", "b"}
+	v.Motd = "hello world"
+	v.Servers = []upstreamTarget{{Host: "s1", Port: 81}}
+	return v
+}
+
+func TestMarshalFormat_YAML(t *testing.T) {
+	t.Parallel()
+	data, err := MarshalFormat(newEncodeSample(), FormatYAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg, err := parseYAML(data)
+	if err != nil {
+		t.Fatalf("generated YAML does not parse: %v\n%s", err, data)
+	}
+	var back encodeSample
+	if err = FromMap(cfg).Unmarshal("", &back); err != nil || back.Server.Port != 8080 || back.Servers[0].Host != "s1" {
+		t.Errorf("round trip failed: %+v, %v", back, err)
+	}
+	if !strings.HasPrefix(string(data), "motd:") {
+		t.Errorf("expected sorted keys, got:\n%s", data)
+	}
+}
+
+func TestMarshalFormat_JSON(t *testing.T) {
+	t.Parallel()
+	data, err := MarshalFormat(newEncodeSample(), FormatJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = parseJSON(data); err != nil {
+		t.Fatalf("generated JSON does not parse: %v\n%s", err, data)
+	}
+	if !strings.Contains(string(data), "\n  \"motd\": \"hello world\"") {
+		t.Errorf("expected indented JSON, got:\n%s", data)
+	}
+}
+
+func TestMarshalFormat_Env(t *testing.T) {
+	t.Parallel()
+	data, err := MarshalFormat(newEncodeSample(), FormatEnv, WithEnvPrefix("APP_"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := strings.Join([]string{
+		`APP_MOTD=hello world`,
+		`APP_SERVER__HOST=localhost`,
+		`APP_SERVER__PORT=8080`,
+		`APP_SERVER__TAGS__0=a`,
+		`APP_SERVER__TAGS__1=b`,
+		`APP_SERVERS__0__HOST=s1`,
+		`APP_SERVERS__0__PORT=81`,
+		`APP_SERVERS__0__TIMEOUT=0s`,
+	}, "\n") + "\n"
+	if string(data) != want {
+		t.Errorf("unexpected env output:\n%s", data)
+	}
+}
+
+func TestMarshalFormat_EnvRoundTrip(t *testing.T) {
+	prefix := "TESTENCODEENV_"
+	data, err := MarshalFormat(newEncodeSample(), FormatEnv, WithEnvPrefix(prefix))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		t.Cleanup(func() { os.Unsetenv(key) })
+		os.Setenv(key, value)
+	}
+
+	cfg, err := New(WithLoader(FromEnv(prefix)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var back encodeSample
+	if err = cfg.Unmarshal("", &back); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if back.Server.Host != "localhost" || back.Server.Port != 8080 || len(back.Server.Tags) != 2 ||
+		back.Motd != "hello world" || len(back.Servers) != 1 || back.Servers[0].Port != 81 {
+		t.Errorf("env round trip failed: %+v", back)
+	}
+}
+
+func TestMarshalFormat_Unsupported(t *testing.T) {
+	t.Parallel()
+	if _, err := MarshalFormat(newEncodeSample(), Format("ini")); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestEnvKeyMapping(t *testing.T) {
+	t.Parallel()
+	if got := configKeyToEnv("db.max_conns", "APP_"); got != "APP_DB__MAX_CONNS" {
+		t.Errorf("unexpected env key %q", got)
+	}
+	if got := envToConfigKey("APP_DB__MAX_CONNS", "APP_"); got != "db.max_conns" {
+		t.Errorf("unexpected config key %q", got)
+	}
+}
+
+func newEncodeConfig() *Config {
+	return &Config{
+		values: map[string]any{
+			"db": map[string]any{
+				"host":     "localhost",
+				"password": "hunter2",
+				"empty":    map[string]any{},
+			},
+			"port":    8080,
+			"servers": []any{map[string]any{"host": "a"}},
+		},
+		sensitive: newSensitiveKeys([]string{"db.password"}, nil),
+		sources:   sourceMap{"db.host": "config.yaml", "db.password": "env:APP_", "port": "config.yaml", "servers": "servers.json"},
+	}
+}
+
+func TestConfig_Encode(t *testing.T) {
+	t.Parallel()
+	cases := map[Format]string{
+		FormatYAML: "db:\n  empty: {}\n  host: localhost\n  password: hunter2\nport: 8080\nservers:\n- host: a\n",
+		FormatJSON: "{\n  \"db\": {\n    \"empty\": {},\n    \"host\": \"localhost\",\n    \"password\": \"hunter2\"\n  },\n  \"port\": 8080,\n  \"servers\": [\n    {\n      \"host\": \"a\"\n    }\n  ]\n}\n",
+		FormatTOML: "port = 8080\n\n[db]\nhost = \"localhost\"\npassword = \"hunter2\"\n\n[db.empty]\n\n[[servers]]\nhost = \"a\"\n",
+		FormatEnv:  "DB__HOST=localhost\nDB__PASSWORD=hunter2\nPORT=8080\nSERVERS__0__HOST=a\n",
+	}
+	for format, want := range cases {
+		var buf strings.Builder
+		if err := newEncodeConfig().Encode(&buf, format); err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if buf.String() != want {
+			t.Errorf("%s: unexpected output:\n%s\nwant:\n%s", format, buf.String(), want)
+		}
+	}
+}
+
+func TestConfig_EncodeRedactedWithProvenance(t *testing.T) {
+	t.Parallel()
+	cases := map[Format][]string{
+		FormatYAML: {"host: localhost # config.yaml", "password: \"[REDACTED]\" # env:APP_", "servers: # servers.json"},
+		FormatTOML: {"# config.yaml\nhost = \"localhost\"", "# env:APP_\npassword = \"[REDACTED]\"", "# servers.json\nhost = \"a\""},
+		FormatEnv:  {"# config.yaml\nAPP_DB__HOST=localhost", "# env:APP_\nAPP_DB__PASSWORD=[REDACTED]"},
+	}
+	for format, wants := range cases {
+		var buf strings.Builder
+		err := newEncodeConfig().Encode(&buf, format, WithRedaction(), WithProvenance(), WithEnvPrefix("APP_"))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		out := buf.String()
+		if strings.Contains(out, "hunter2") {
+			t.Errorf("%s: leaked secret:\n%s", format, out)
+		}
+		for _, want := range wants {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s: expected %q in:\n%s", format, want, out)
+			}
+		}
+	}
+}
+
+func TestConfig_EncodeJSONProvenanceUnsupported(t *testing.T) {
+	t.Parallel()
+	var buf strings.Builder
+	if err := newEncodeConfig().Encode(&buf, FormatJSON, WithProvenance()); err == nil {
+		t.Error("expected error for JSON provenance comments")
+	}
+}
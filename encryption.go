@@ -0,0 +1,186 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	encPrefix = "ENC["
+	encSuffix = "]"
+)
+
+func Encrypt(plaintext string, key []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("config: cannot generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encPrefix + base64.StdEncoding.EncodeToString(sealed) + encSuffix, nil
+}
+
+func Decrypt(value string, key []byte) (string, error) {
+	if !IsEncrypted(value) {
+		return "", fmt.Errorf("%w: value is not in ENC[...] format", ErrDecrypt)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	payload := strings.TrimSuffix(strings.TrimPrefix(value, encPrefix), encSuffix)
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", errors.Join(ErrDecrypt, err)
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("%w: ciphertext too short", ErrDecrypt)
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.Join(ErrDecrypt, err)
+	}
+
+	return string(plaintext), nil
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encPrefix) && strings.HasSuffix(value, encSuffix)
+}
+
+func GenerateKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("config: cannot generate key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("config: invalid base64 key: %w", err)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("config: invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+type keySource func() ([]byte, error)
+
+func staticKey(key []byte) keySource {
+	return func() ([]byte, error) {
+		return key, nil
+	}
+}
+
+func keyFromEnv(envVar string) keySource {
+	return func() ([]byte, error) {
+		encoded, ok := os.LookupEnv(envVar)
+		if !ok || encoded == "" {
+			return nil, fmt.Errorf("%w: environment variable %s is not set", ErrNoDecryptionKey, envVar)
+		}
+		return ParseKey(encoded)
+	}
+}
+
+func keyFromFile(path string) keySource {
+	return func() ([]byte, error) {
+		data, err := os.ReadFile(path) // #nosec G304 -- key file path is provided by the application
+		if err != nil {
+			return nil, fmt.Errorf("config: cannot read key file: %w", err)
+		}
+		return ParseKey(string(data))
+	}
+}
+
+type decrypter struct {
+	source    keySource
+	key       []byte
+	decrypted []string
+}
+
+func (d *decrypter) resolveKey() ([]byte, error) {
+	if d.key != nil {
+		return d.key, nil
+	}
+	if d.source == nil {
+		return nil, ErrNoDecryptionKey
+	}
+	key, err := d.source()
+	if err != nil {
+		return nil, err
+	}
+	d.key = key
+	return key, nil
+}
+
+func (d *decrypter) decryptValue(v any, path string) (any, error) {
+	switch val := v.(type) {
+	case string:
+		if !IsEncrypted(val) {
+			return val, nil
+		}
+		key, err := d.resolveKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", path, err)
+		}
+		plaintext, err := Decrypt(val, key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", path, err)
+		}
+		d.decrypted = append(d.decrypted, trimIndex(path))
+		return plaintext, nil
+
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			decrypted, err := d.decryptValue(item, childPath)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = decrypted
+		}
+		return out, nil
+
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			decrypted, err := d.decryptValue(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = decrypted
+		}
+		return out, nil
+
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKey(t *testing.T) []byte {
+	t.Helper()
+	encoded, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	key, err := ParseKey(encoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return key
+}
+
+func TestEncrypt_RoundTrip(t *testing.T) {
+	t.Parallel()
+	key := testKey(t)
+	enc, err := Encrypt("s3cr3t", key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !IsEncrypted(enc) {
+		t.Fatalf("expected ENC[...] format, got %q", enc)
+	}
+	plain, err := Decrypt(enc, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plain != "s3cr3t" {
+		t.Errorf("expected s3cr3t, got %q", plain)
+	}
+}
+
+func TestEncrypt_InvalidKey(t *testing.T) {
+	t.Parallel()
+	if _, err := Encrypt("x", []byte("short")); err == nil {
+		t.Fatal("expected error for invalid key size")
+	}
+}
+
+func TestDecrypt_WrongKey(t *testing.T) {
+	t.Parallel()
+	enc, _ := Encrypt("x", testKey(t))
+	_, err := Decrypt(enc, testKey(t))
+	if !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+}
+
+func TestDecrypt_NotEncrypted(t *testing.T) {
+	t.Parallel()
+	_, err := Decrypt("plain", testKey(t))
+	if !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+}
+
+func TestDecrypt_BadPayload(t *testing.T) {
+	t.Parallel()
+	key := testKey(t)
+	if _, err := Decrypt("ENC[!!!]", key); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt for bad base64, got %v", err)
+	}
+	short := "ENC[" + base64.StdEncoding.EncodeToString([]byte("abc")) + "]"
+	if _, err := Decrypt(short, key); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt for short payload, got %v", err)
+	}
+}
+
+func TestParseKey_Invalid(t *testing.T) {
+	t.Parallel()
+	if _, err := ParseKey("not base64!"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestNew_DecryptsValues(t *testing.T) {
+	t.Parallel()
+	key := testKey(t)
+	enc, _ := Encrypt("hunter2", key)
+	data := map[string]any{
+		"db":    map[string]any{"password": enc, "user": "admin"},
+		"items": []any{enc, "plain"},
+	}
+	cfg, err := New(WithLoader(&staticLoader{data: data}), WithDecryptionKey(key))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GetString("db.password") != "hunter2" {
+		t.Errorf("expected decrypted password, got %q", cfg.GetString("db.password"))
+	}
+	items := cfg.GetStringSlice("items")
+	if len(items) != 2 || items[0] != "hunter2" || items[1] != "plain" {
+		t.Errorf("unexpected items: %v", items)
+	}
+}
+
+func TestNew_EncryptedWithoutKey(t *testing.T) {
+	t.Parallel()
+	enc, _ := Encrypt("x", testKey(t))
+	_, err := New(WithLoader(&staticLoader{data: map[string]any{"k": enc}}))
+	if !errors.Is(err, ErrNoDecryptionKey) {
+		t.Fatalf("expected ErrNoDecryptionKey, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `"k"`) {
+		t.Errorf("expected key in error, got %v", err)
+	}
+}
+
+func TestNew_NoEncryptedValuesNoKeyNeeded(t *testing.T) {
+	t.Parallel()
+	_, err := New(
+		WithLoader(&staticLoader{data: map[string]any{"k": "v"}}),
+		WithDecryptionKeyFromEnv("CONFIG_TEST_UNSET_KEY_VAR"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNew_DecryptionKeyFromEnv(t *testing.T) {
+	encoded, _ := GenerateKey()
+	key, _ := ParseKey(encoded)
+	enc, _ := Encrypt("from-env", key)
+	t.Setenv("CONFIG_TEST_KEY", encoded)
+
+	cfg, err := New(
+		WithLoader(&staticLoader{data: map[string]any{"k": enc}}),
+		WithDecryptionKeyFromEnv("CONFIG_TEST_KEY"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GetString("k") != "from-env" {
+		t.Errorf("expected from-env, got %q", cfg.GetString("k"))
+	}
+}
+
+func TestNew_DecryptionKeyFromEnv_Missing(t *testing.T) {
+	t.Parallel()
+	enc, _ := Encrypt("x", testKey(t))
+	_, err := New(
+		WithLoader(&staticLoader{data: map[string]any{"k": enc}}),
+		WithDecryptionKeyFromEnv("CONFIG_TEST_UNSET_KEY_VAR"),
+	)
+	if !errors.Is(err, ErrNoDecryptionKey) {
+		t.Fatalf("expected ErrNoDecryptionKey, got %v", err)
+	}
+}
+
+func TestNew_DecryptionKeyFile(t *testing.T) {
+	t.Parallel()
+	encoded, _ := GenerateKey()
+	key, _ := ParseKey(encoded)
+	enc, _ := Encrypt("from-file", key)
+
+	path := filepath.Join(t.TempDir(), "config.key")
+	if err := os.WriteFile(path, []byte(encoded+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := New(
+		WithLoader(&staticLoader{data: map[string]any{"k": enc}}),
+		WithDecryptionKeyFile(path),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GetString("k") != "from-file" {
+		t.Errorf("expected from-file, got %q", cfg.GetString("k"))
+	}
+}
+
+func TestNew_DecryptionKeyFile_Missing(t *testing.T) {
+	t.Parallel()
+	enc, _ := Encrypt("x", testKey(t))
+	_, err := New(
+		WithLoader(&staticLoader{data: map[string]any{"k": enc}}),
+		WithDecryptionKeyFile(filepath.Join(t.TempDir(), "missing.key")),
+	)
+	if err == nil {
+		t.Fatal("expected error for missing key file")
+	}
+}
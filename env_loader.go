@@ -23,10 +23,21 @@ func (l *EnvLoader) apply(b *builder) {
 	b.loaders = append(b.loaders, l)
 }
 
+func (l *EnvLoader) sourceName() string {
+	if l.prefix == "" {
+		return "env"
+	}
+	return "env:" + l.prefix
+}
+
 func (l *EnvLoader) Load() (map[string]any, error) {
+	return l.parse(os.Environ()), nil
+}
+
+func (l *EnvLoader) parse(environ []string) map[string]any {
 	cfg := make(map[string]any)
 
-	for _, env := range os.Environ() {
+	for _, env := range environ {
 		if !strings.HasPrefix(env, l.prefix) {
 			continue
 		}
@@ -39,8 +50,7 @@ func (l *EnvLoader) Load() (map[string]any, error) {
 		key := parts[0]
 		value := parts[1]
 
-		configKey := strings.ToLower(strings.TrimPrefix(key, l.prefix))
-		configKey = strings.ReplaceAll(configKey, "__", ".")
+		configKey := envToConfigKey(key, l.prefix)
 
 		var parsed any = value
 		if l.autoTypeParse {
@@ -50,5 +60,14 @@ func (l *EnvLoader) Load() (map[string]any, error) {
 		setNested(cfg, configKey, parsed)
 	}
 
-	return cfg, nil
+	return cfg
+}
+
+func envToConfigKey(env, prefix string) string {
+	key := strings.ToLower(strings.TrimPrefix(env, prefix))
+	return strings.ReplaceAll(key, "__", ".")
+}
+
+func configKeyToEnv(key, prefix string) string {
+	return prefix + strings.ToUpper(strings.ReplaceAll(key, ".", "__"))
 }
@@ -7,9 +7,13 @@ import (
 )
 
 var (
-	ErrNoConfigSource = errors.New("no valid configuration source found")
-	ErrParseYAML      = errors.New("failed to parse YAML")
-	ErrParseJSON      = errors.New("failed to parse JSON")
+	ErrNoConfigSource  = errors.New("no valid configuration source found")
+	ErrParseYAML       = errors.New("failed to parse YAML")
+	ErrParseJSON       = errors.New("failed to parse JSON")
+	ErrParseTOML       = errors.New("failed to parse TOML")
+	ErrParseEnv        = errors.New("failed to parse env")
+	ErrDecrypt         = errors.New("failed to decrypt value")
+	ErrNoDecryptionKey = errors.New("no decryption key configured")
 )
 
 type LoadErrorDetail struct {
@@ -44,8 +48,78 @@ func (e *LoadError) Unwrap() error {
 	return ErrNoConfigSource
 }
 
+type ViolationCode string
+
+const (
+	CodeRequired   ViolationCode = "required"
+	CodeRange      ViolationCode = "range"
+	CodeEnum       ViolationCode = "enum"
+	CodePattern    ViolationCode = "pattern"
+	CodeType       ViolationCode = "type"
+	CodeUnknown    ViolationCode = "unknown"
+	CodeConflict   ViolationCode = "conflict"
+	CodeFormat     ViolationCode = "format"
+	CodeLength     ViolationCode = "length"
+	CodeCustom     ViolationCode = "custom"
+	CodeDeprecated ViolationCode = "deprecated"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+type Violation struct {
+	Key      string
+	Code     ViolationCode
+	Value    any
+	Message  string
+	Source   string
+	Severity Severity
+	err      error
+}
+
+func (v Violation) Error() string {
+	if v.Key == "" {
+		return v.Message
+	}
+	return fmt.Sprintf("%q: %s", v.Key, v.Message)
+}
+
+func (v Violation) Unwrap() error {
+	return v.err
+}
+
+func asViolation(err error) Violation {
+	if v, ok := err.(Violation); ok {
+		return v
+	}
+	return Violation{Code: CodeCustom, Message: err.Error(), err: err}
+}
+
 type ValidationError struct {
 	Violations []string
+	Details    []Violation
+	errs       []error
+}
+
+func newValidationError(errs []error) *ValidationError {
+	violations := make([]string, len(errs))
+	details := make([]Violation, len(errs))
+	for i, err := range errs {
+		violations[i] = err.Error()
+		details[i] = asViolation(err)
+	}
+	return &ValidationError{Violations: violations, Details: details, errs: errs}
 }
 
 func (e *ValidationError) Error() string {
@@ -59,3 +133,87 @@ func (e *ValidationError) Error() string {
 
 	return b.String()
 }
+
+func (e *ValidationError) Unwrap() []error {
+	return e.errs
+}
+
+type UnknownKey struct {
+	Path       string
+	Suggestion string
+}
+
+type UnknownKeysError struct {
+	Keys []UnknownKey
+}
+
+func (e *UnknownKeysError) Error() string {
+	var b strings.Builder
+	b.WriteString("config: unknown keys:")
+
+	for _, k := range e.Keys {
+		b.WriteString("\n  - ")
+		fmt.Fprintf(&b, "%q", k.Path)
+		if k.Suggestion != "" {
+			fmt.Fprintf(&b, " (did you mean %q?)", k.Suggestion)
+		}
+	}
+
+	return b.String()
+}
+
+type FieldError struct {
+	Path   string
+	Field  string
+	Type   string
+	Value  any
+	Source string
+	Err    error
+}
+
+func (e FieldError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Path)
+
+	if s, ok := e.Value.(string); ok {
+		fmt.Fprintf(&b, ": invalid value %q", s)
+	} else {
+		fmt.Fprintf(&b, ": invalid value %v", e.Value)
+	}
+	fmt.Fprintf(&b, " for %s (%s)", e.Field, e.Type)
+
+	if e.Source != "" {
+		fmt.Fprintf(&b, " from %s", e.Source)
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+
+	return b.String()
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+type UnmarshalError struct {
+	Errors []FieldError
+}
+
+func (e *UnmarshalError) Error() string {
+	var b strings.Builder
+	b.WriteString("config: unmarshal failed:")
+
+	for _, fe := range e.Errors {
+		b.WriteString("\n  - ")
+		b.WriteString(fe.Error())
+	}
+
+	return b.String()
+}
+
+func (e *UnmarshalError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, fe := range e.Errors {
+		errs[i] = fe
+	}
+	return errs
+}
@@ -61,3 +61,74 @@ func TestValidationError_Empty(t *testing.T) {
 		t.Error("expected header even with no violations")
 	}
 }
+
+func TestNewValidationError_Details(t *testing.T) {
+	t.Parallel()
+	plain := errors.New("something broke")
+	e := newValidationError([]error{missingKeyError("a"), plain})
+	if e.Details[0].Key != "a" || e.Details[0].Code != CodeRequired {
+		t.Errorf("unexpected detail: %+v", e.Details[0])
+	}
+	if e.Details[1].Code != CodeCustom || e.Details[1].Message != "something broke" || !errors.Is(e.Details[1], plain) {
+		t.Errorf("unexpected detail for plain error: %+v", e.Details[1])
+	}
+}
+
+func TestViolation_Error(t *testing.T) {
+	t.Parallel()
+	if got := (Violation{Key: "a.b", Message: "bad"}).Error(); got != `"a.b": bad` {
+		t.Errorf("unexpected error: %s", got)
+	}
+	if got := (Violation{Message: "bad"}).Error(); got != "bad" {
+		t.Errorf("unexpected error without key: %s", got)
+	}
+}
+
+func TestUnknownKeysError_Error(t *testing.T) {
+	t.Parallel()
+	err := &UnknownKeysError{Keys: []UnknownKey{
+		{Path: "databse.host", Suggestion: "database.host"},
+		{Path: "extra"},
+	}}
+	want := "config: unknown keys:\n  - \"databse.host\" (did you mean \"database.host\"?)\n  - \"extra\""
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestFieldError_Error(t *testing.T) {
+	t.Parallel()
+	err := FieldError{
+		Path:   "server.http.port",
+		Field:  "Server.HTTP.Port",
+		Type:   "int",
+		Value:  "abc",
+		Source: "config.yaml",
+		Err:    errors.New("not a number"),
+	}
+	want := `server.http.port: invalid value "abc" for Server.HTTP.Port (int) from config.yaml: not a number`
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+
+	err.Value, err.Source = 42, ""
+	want = `server.http.port: invalid value 42 for Server.HTTP.Port (int): not a number`
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestUnmarshalError_Unwrap(t *testing.T) {
+	t.Parallel()
+	cause := errors.New("cause")
+	err := &UnmarshalError{Errors: []FieldError{
+		{Path: "a", Field: "A", Type: "int", Value: 1, Err: errors.New("other")},
+		{Path: "b", Field: "B", Type: "int", Value: 2, Err: cause},
+	}}
+	if !errors.Is(err, cause) {
+		t.Error("expected errors.Is to find the field cause")
+	}
+	if !strings.HasPrefix(err.Error(), "config: unmarshal failed:\n  - a: ") {
+		t.Errorf("unexpected message: %s", err.Error())
+	}
+}
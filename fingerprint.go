@@ -0,0 +1,94 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+)
+
+type FingerprintOption interface {
+	applyFingerprint(o *fingerprintOptions)
+}
+
+type fingerprintOptions struct {
+	exclude []string
+}
+
+type fingerprintOptionFunc func(*fingerprintOptions)
+
+func (f fingerprintOptionFunc) applyFingerprint(o *fingerprintOptions) { f(o) }
+
+func WithExcludedKeys(patterns ...string) FingerprintOption {
+	return fingerprintOptionFunc(func(o *fingerprintOptions) {
+		o.exclude = append(o.exclude, patterns...)
+	})
+}
+
+func (c *Config) Fingerprint(opts ...FingerprintOption) (string, error) {
+	o := &fingerprintOptions{}
+	for _, opt := range opts {
+		opt.applyFingerprint(o)
+	}
+
+	data, err := json.Marshal(o.canonical(c.values, ""))
+	if err != nil {
+		return "", fmt.Errorf("config: fingerprint: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (o *fingerprintOptions) canonical(v any, key string) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			child := joinKey(key, k)
+			if o.excluded(child) {
+				continue
+			}
+			out[k] = o.canonical(item, child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = o.canonical(item, key)
+		}
+		return out
+	case int:
+		return json.Number(strconv.Itoa(val))
+	case int64:
+		return json.Number(strconv.FormatInt(val, 10))
+	case uint64:
+		return json.Number(strconv.FormatUint(val, 10))
+	case float64:
+		return canonicalFloat(val)
+	default:
+		return val
+	}
+}
+
+func (o *fingerprintOptions) excluded(key string) bool {
+	for _, pattern := range o.exclude {
+		if ok, err := path.Match(pattern, key); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+func canonicalFloat(f float64) any {
+	switch {
+	case math.IsNaN(f) || math.IsInf(f, 0):
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	case f == math.Trunc(f) && math.Abs(f) < 1<<53:
+		return json.Number(strconv.FormatInt(int64(f), 10))
+	default:
+		return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+}
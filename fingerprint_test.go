@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"testing"
+)
+
+func TestConfig_Fingerprint(t *testing.T) {
+	t.Parallel()
+	a := newTestConfig(map[string]any{
+		"db":   map[string]any{"host": "h", "port": 5432, "ratio": 0.25},
+		"tags": []any{"x", uint64(2)},
+	})
+	b := newTestConfig(map[string]any{
+		"tags": []any{"x", 2.0},
+		"db":   map[string]any{"ratio": 0.25, "port": float64(5432), "host": "h"},
+	})
+
+	fa, err := a.Fingerprint()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{64}$`).MatchString(fa) {
+		t.Errorf("unexpected fingerprint format %q", fa)
+	}
+	if fb, _ := b.Fingerprint(); fb != fa {
+		t.Errorf("expected equal fingerprints for equivalent values, got %s and %s", fa, fb)
+	}
+	if again, _ := a.Fingerprint(); again != fa {
+		t.Error("expected fingerprint to be deterministic")
+	}
+
+	for name, values := range map[string]map[string]any{
+		"changed value": {"db": map[string]any{"host": "other", "port": 5432, "ratio": 0.25}, "tags": []any{"x", 2}},
+		"string number": {"db": map[string]any{"host": "h", "port": "5432", "ratio": 0.25}, "tags": []any{"x", 2}},
+		"list order":    {"db": map[string]any{"host": "h", "port": 5432, "ratio": 0.25}, "tags": []any{2, "x"}},
+		"flat key":      {"db.host": "h", "db": map[string]any{"port": 5432, "ratio": 0.25}, "tags": []any{"x", 2}},
+	} {
+		if f, _ := newTestConfig(values).Fingerprint(); f == fa {
+			t.Errorf("%s: expected a different fingerprint", name)
+		}
+	}
+}
+
+func TestConfig_FingerprintExcludedKeys(t *testing.T) {
+	t.Parallel()
+	a := newTestConfig(map[string]any{"app": map[string]any{"name": "svc", "started_at": "10:00"}, "build": map[string]any{"id": 1}})
+	b := newTestConfig(map[string]any{"app": map[string]any{"name": "svc", "started_at": "11:00"}, "build": map[string]any{"id": 2}})
+
+	fa, _ := a.Fingerprint(WithExcludedKeys("*.started_at", "build"))
+	fb, _ := b.Fingerprint(WithExcludedKeys("*.started_at", "build"))
+	if fa != fb {
+		t.Error("expected excluded keys to be ignored")
+	}
+	if fc, _ := b.Fingerprint(WithExcludedKeys("build")); fc == fa {
+		t.Error("expected non-excluded keys to affect the fingerprint")
+	}
+}
+
+func TestCanonicalFloat(t *testing.T) {
+	t.Parallel()
+	cases := map[float64]string{
+		3:           "3",
+		-0.0:        "0",
+		0.1:         "0.1",
+		1e21:        "1e+21",
+		math.Inf(1): "+Inf",
+		math.NaN():  "NaN",
+	}
+	for f, want := range cases {
+		if got := fmt.Sprint(canonicalFloat(f)); got != want {
+			t.Errorf("canonicalFloat(%v) = %s, want %s", f, got, want)
+		}
+	}
+}
@@ -2,4 +2,10 @@ module github.com/shuldan/config
 
 go 1.24.2
 
-require github.com/goccy/go-yaml v1.18.0
+require (
+	filippo.io/age v1.2.1
+	github.com/goccy/go-yaml v1.18.0
+	golang.org/x/crypto v0.24.0
+)
+
+require golang.org/x/sys v0.21.0 // indirect
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+type defaultsSetter interface {
+	SetDefaults()
+}
+
+type validator interface {
+	Validate() error
+}
+
+type hookFunc func(v reflect.Value, at fieldPath)
+
+func (d *decoder) runHooks(rv reflect.Value, at fieldPath) {
+	d.walk(rv, at, func(v reflect.Value, _ fieldPath) {
+		if h, ok := hookTarget[defaultsSetter](v); ok {
+			h.SetDefaults()
+		}
+	})
+
+	d.walk(rv, at, func(v reflect.Value, at fieldPath) {
+		h, ok := hookTarget[validator](v)
+		if !ok {
+			return
+		}
+		if err := h.Validate(); err != nil {
+			d.violations = append(d.violations, Violation{
+				Key:     at.key,
+				Code:    CodeCustom,
+				Message: err.Error(),
+				Source:  d.sources.lookup(at.key),
+				err:     err,
+			})
+		}
+	})
+}
+
+func (d *decoder) walk(v reflect.Value, at fieldPath, visit hookFunc) {
+	if v.Kind() == reflect.Pointer {
+		if !v.IsNil() {
+			d.walk(v.Elem(), at, visit)
+		}
+		return
+	}
+
+	d.walkChildren(v, at, visit)
+	visit(v, at)
+}
+
+func (d *decoder) walkChildren(v reflect.Value, at fieldPath, visit hookFunc) {
+	switch {
+	case v.Kind() == reflect.Struct && isNestedStruct(v.Type()):
+		d.walkFields(v, at, visit)
+
+	case v.Kind() == reflect.Slice && !hasCustomDecoder(v.Type()):
+		for i := 0; i < v.Len(); i++ {
+			d.walk(v.Index(i), at.index(i), visit)
+		}
+
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String && v.CanInterface():
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+		for _, k := range keys {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(k))
+			d.walk(elem, at.mapKey(k.String()), visit)
+			v.SetMapIndex(k, elem)
+		}
+	}
+}
+
+func (d *decoder) walkFields(v reflect.Value, at fieldPath, visit hookFunc) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag, ok := parseFieldTag(field, d.naming)
+		if !ok {
+			continue
+		}
+
+		fv := v.Field(i)
+		switch {
+		case tag.squash && field.Anonymous:
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			d.walkChildren(fv, at.embed(field.Name), visit)
+		case tag.squash:
+			d.walk(fv, at.embed(field.Name), visit)
+		default:
+			d.walk(fv, at.child(tag.name, field.Name), visit)
+		}
+	}
+}
+
+func hookTarget[T any](v reflect.Value) (T, bool) {
+	if v.CanAddr() {
+		if p := v.Addr(); p.CanInterface() {
+			if h, ok := p.Interface().(T); ok {
+				return h, true
+			}
+		}
+	}
+	if v.IsValid() && v.CanInterface() {
+		h, ok := v.Interface().(T)
+		return h, ok
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var errNoUpstreams = errors.New("at least one upstream is required")
+
+type hookUpstream struct {
+	Host string `cfg:"host"`
+	Port int    `cfg:"port"`
+}
+
+func (u *hookUpstream) SetDefaults() {
+	if u.Port == 0 {
+		u.Port = 80
+	}
+}
+
+func (u hookUpstream) Validate() error {
+	if u.Host == "" {
+		return errors.New("host is empty")
+	}
+	return nil
+}
+
+type hookBase struct {
+	Name string `cfg:"name"`
+}
+
+func (b *hookBase) Validate() error {
+	if b.Name == "" {
+		return errors.New("name is empty")
+	}
+	return nil
+}
+
+type hookService struct {
+	hookBase
+	Upstreams []hookUpstream           `cfg:"upstreams"`
+	Named     map[string]*hookUpstream `cfg:"named"`
+	Backup    *hookUpstream            `cfg:"backup"`
+	Mode      string                   `cfg:"mode"`
+	order     []string
+}
+
+func (s *hookService) SetDefaults() {
+	if s.Mode == "" {
+		s.Mode = "fast"
+	}
+	s.order = append(s.order, "defaults")
+}
+
+func (s *hookService) Validate() error {
+	s.order = append(s.order, "validate")
+	if len(s.Upstreams) == 0 {
+		return errNoUpstreams
+	}
+	for _, u := range s.Upstreams {
+		if u.Port == 0 {
+			return errors.New("defaults must run before validation")
+		}
+	}
+	return s.hookBase.Validate()
+}
+
+func TestUnmarshal_LifecycleHooks(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"svc": map[string]any{
+			"name":      "orders",
+			"upstreams": []any{map[string]any{"host": "a"}, map[string]any{"host": "b", "port": 8080}},
+			"named":     map[string]any{"main": map[string]any{"host": "m"}},
+		},
+	})
+	var target hookService
+	if err := cfg.Unmarshal("svc", &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Mode != "fast" || target.Upstreams[0].Port != 80 || target.Upstreams[1].Port != 8080 {
+		t.Errorf("expected defaults to be applied: %+v", target)
+	}
+	if target.Named["main"].Port != 80 {
+		t.Errorf("expected defaults inside maps: %+v", target.Named["main"])
+	}
+	if strings.Join(target.order, ",") != "defaults,validate" {
+		t.Errorf("unexpected hook order: %v", target.order)
+	}
+}
+
+func TestUnmarshal_LifecycleHookErrors(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"svc": map[string]any{
+			"named":  map[string]any{"main": map[string]any{"port": 1}},
+			"backup": map[string]any{"port": 2},
+		},
+	})
+	var target hookService
+	err := cfg.Unmarshal("svc", &target)
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	want := []string{
+		`"svc.named.main": host is empty`,
+		`"svc.backup": host is empty`,
+		`"svc": at least one upstream is required`,
+	}
+	if strings.Join(ve.Violations, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected violations:\n%s", strings.Join(ve.Violations, "\n"))
+	}
+	if !errors.Is(err, errNoUpstreams) {
+		t.Error("expected errors.Is to reach the hook error")
+	}
+}
+
+func TestUnmarshal_ReportsAllErrorKinds(t *testing.T) {
+	t.Parallel()
+	type target struct {
+		hookBase
+		Host string `cfg:"host,required"`
+		Port int    `cfg:"port"`
+		Mode string `cfg:"mode" validate:"oneof=ro rw"`
+	}
+	cfg := newTestConfig(map[string]any{"port": "abc", "mode": "wo"})
+	var out target
+	err := cfg.Unmarshal("", &out)
+
+	var ue *UnmarshalError
+	if !errors.As(err, &ue) || len(ue.Errors) != 1 || ue.Errors[0].Path != "port" {
+		t.Errorf("expected port conversion error, got %v", err)
+	}
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError alongside UnmarshalError, got %v", err)
+	}
+	want := []string{
+		`"host": required key is missing`,
+		`"mode": value "wo" is not one of [ro rw]`,
+		"name is empty",
+	}
+	if strings.Join(ve.Violations, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected violations:\n%s", strings.Join(ve.Violations, "\n"))
+	}
+}
+
+func TestUnmarshal_RootValidateHook(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{})
+	var target hookBase
+	err := cfg.Unmarshal("", &target)
+	if err == nil || err.Error() != "config: validation failed:\n  - name is empty" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
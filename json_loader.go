@@ -1,15 +1,20 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"io"
 	"os"
+	"strconv"
 )
 
 type jsonLoader struct {
-	paths    []string
-	basePath string
-	optional bool
+	paths     []string
+	basePath  string
+	optional  bool
+	decrypted []string
+	source    string
 }
 
 func FromJSON(paths ...string) *jsonLoader {
@@ -30,6 +35,14 @@ func (l *jsonLoader) apply(b *builder) {
 	b.loaders = append(b.loaders, l)
 }
 
+func (l *jsonLoader) sensitiveKeys() []string {
+	return l.decrypted
+}
+
+func (l *jsonLoader) sourceName() string {
+	return l.source
+}
+
 func (l *jsonLoader) Load() (map[string]any, error) {
 	var details []LoadErrorDetail
 
@@ -51,12 +64,18 @@ func (l *jsonLoader) Load() (map[string]any, error) {
 			continue
 		}
 
-		var cfg map[string]any
-		if err = json.Unmarshal(data, &cfg); err != nil {
-			return nil, errors.Join(ErrParseJSON, err)
+		l.source = path
+		cfg, err := parseJSON(data)
+		if err != nil {
+			return nil, err
 		}
 
-		return normalizeMap(cfg), nil
+		if isSOPSDocument(cfg) {
+			cfg, l.decrypted, err = decryptSOPSDocument(data, cfg, &sopsKeyring{})
+			return cfg, err
+		}
+
+		return cfg, nil
 	}
 
 	if l.optional {
@@ -65,3 +84,34 @@ func (l *jsonLoader) Load() (map[string]any, error) {
 
 	return nil, &LoadError{Message: "no valid JSON configuration source found", Details: details}
 }
+
+func parseJSON(data []byte) (map[string]any, error) {
+	var cfg map[string]any
+	if err := decodeJSON(data, &cfg); err != nil {
+		return nil, errors.Join(ErrParseJSON, err)
+	}
+	return normalizeMap(cfg), nil
+}
+
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+func jsonNumber(n json.Number) any {
+	if i, err := n.Int64(); err == nil {
+		return autoParseString(strconv.FormatInt(i, 10))
+	}
+	if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+		return u
+	}
+	f, _ := n.Float64()
+	return f
+}
@@ -25,7 +25,7 @@ func TestJsonLoader_Load_Success(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if cfg["port"] != float64(8080) {
+	if cfg["port"] != 8080 {
 		t.Errorf("expected 8080, got %v", cfg["port"])
 	}
 }
@@ -3,3 +3,7 @@ package config
 type Loader interface {
 	Load() (map[string]any, error)
 }
+
+type sensitiveSource interface {
+	sensitiveKeys() []string
+}
@@ -7,3 +7,22 @@ type Logger interface {
 type nopLogger struct{}
 
 func (nopLogger) Debug(string, ...any) {}
+
+type warnLogger interface {
+	Warn(msg string, args ...any)
+}
+
+func (b *builder) warningHandler() func(Violation) {
+	if b.onWarning != nil {
+		return b.onWarning
+	}
+	logger := b.logger
+	return func(v Violation) {
+		args := []any{"key", v.Key, "code", v.Code, "message", v.Message, "source", v.Source}
+		if w, ok := logger.(warnLogger); ok {
+			w.Warn("config: validation warning", args...)
+			return
+		}
+		logger.Debug("config: validation warning", args...)
+	}
+}
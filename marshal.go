@@ -0,0 +1,208 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+)
+
+func Marshal(v any, opts ...EncodeOption) (map[string]any, error) {
+	o := newEncodeOptions(opts)
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("config: marshal source must be a non-nil struct")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: marshal source must be a struct, got %s", rv.Kind())
+	}
+
+	out := make(map[string]any)
+	if err := marshalFields(rv, "", o.naming, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func marshalFields(rv reflect.Value, path string, naming NameMapper, out map[string]any) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		tag, ok := parseFieldTag(field, naming)
+		if !ok {
+			continue
+		}
+
+		if tag.squash {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if err := marshalFields(fv, path, naming, out); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if (fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface) && fv.IsNil() {
+			continue
+		}
+		if tag.has("omitempty") && fv.IsZero() {
+			continue
+		}
+
+		key := joinKey(path, tag.name)
+		if field.Tag.Get("secret") == "true" {
+			out[tag.name] = redactedValue
+			continue
+		}
+
+		val, err := marshalValue(fv, field.Tag, key, naming)
+		if err != nil {
+			return fmt.Errorf("config: marshal %s: %w", key, err)
+		}
+		out[tag.name] = val
+	}
+	return nil
+}
+
+func marshalValue(v reflect.Value, tag reflect.StructTag, path string, naming NameMapper) (any, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+
+	t := v.Type()
+	switch {
+	case t == durationType:
+		return time.Duration(v.Int()).String(), nil
+	case t == timeType:
+		layout := tag.Get("layout")
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return v.Interface().(time.Time).Format(layout), nil
+	}
+
+	if out, ok, err := marshalWithMarshaler(v); ok {
+		return out, err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.Slice, reflect.Array:
+		return marshalSlice(v, tag, path, naming)
+	case reflect.Map:
+		return marshalMap(v, path, naming)
+	case reflect.Struct:
+		out := make(map[string]any)
+		if err := marshalFields(v, path, naming, out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+func marshalWithMarshaler(v reflect.Value) (any, bool, error) {
+	target := v
+	if v.CanAddr() {
+		target = v.Addr()
+	} else if !v.Type().Implements(textMarshalerType) && !v.Type().Implements(jsonMarshalerType) {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		target = ptr
+	}
+	if !target.CanInterface() {
+		return nil, false, nil
+	}
+
+	switch m := target.Interface().(type) {
+	case encoding.TextMarshaler:
+		text, err := m.MarshalText()
+		if err != nil {
+			return nil, true, err
+		}
+		return string(text), true, nil
+	case json.Marshaler:
+		data, err := m.MarshalJSON()
+		if err != nil {
+			return nil, true, err
+		}
+		var out any
+		if err = decodeJSON(data, &out); err != nil {
+			return nil, true, err
+		}
+		return normalizeValue(out), true, nil
+	case fmt.Stringer:
+		if hasCustomDecoder(v.Type()) {
+			return m.String(), true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func marshalSlice(v reflect.Value, tag reflect.StructTag, path string, naming NameMapper) (any, error) {
+	items := make([]any, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item, err := marshalValue(v.Index(i), tag, indexKey(path, i), naming)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	sep, ok := tag.Lookup("separator")
+	if !ok {
+		return items, nil
+	}
+
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = toString(item)
+	}
+	return strings.Join(parts, sep), nil
+}
+
+func marshalMap(v reflect.Value, path string, naming NameMapper) (any, error) {
+	if v.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("map key type %s is not supported, only string keys", v.Type().Key())
+	}
+
+	out := make(map[string]any, v.Len())
+	for _, k := range v.MapKeys() {
+		item, err := marshalValue(v.MapIndex(k), "", joinKey(path, k.String()), naming)
+		if err != nil {
+			return nil, err
+		}
+		out[k.String()] = item
+	}
+	return out, nil
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type marshalTarget struct {
+	BaseServiceConfig
+	Host     string            `cfg:"host"`
+	Port     int               `cfg:"port"`
+	Ratio    float64           `cfg:"ratio"`
+	Debug    bool              `cfg:"debug"`
+	Timeout  time.Duration     `cfg:"timeout"`
+	Start    time.Time         `cfg:"start" layout:"2006-01-02"`
+	Tags     []string          `cfg:"tags" separator:";"`
+	Ports    []uint16          `cfg:"ports"`
+	Labels   map[string]string `cfg:"labels"`
+	Servers  []upstreamTarget  `cfg:"servers"`
+	Backup   *upstreamTarget   `cfg:"backup"`
+	IP       net.IP            `cfg:"ip"`
+	URL      url.URL           `cfg:"url"`
+	Password string            `cfg:"password" secret:"true"`
+	Token    Secret[string]    `cfg:"token"`
+	Note     string            `cfg:"note,omitempty"`
+	Skipped  string            `cfg:"-"`
+	Nested   struct {
+		Level int `cfg:"level"`
+	} `cfg:"nested"`
+}
+
+func newMarshalTarget() marshalTarget {
+	u, _ := url.Parse("https://example.com/x")
+	v := marshalTarget{
+		Host:     "localhost",
+		Port:     8080,
+		Ratio:    0.5,
+		Debug:    true,
+		Timeout:  1500 * time.Millisecond,
+		Start:    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Tags:     []string{"a", "b"},
+		Ports:    []uint16{80, 443},
+		Labels:   map[string]string{"team": "core"},
+		Servers:  []upstreamTarget{{Host: "s1", Port: 81, Timeout: time.Second}},
+		IP:       net.ParseIP("10.0.0.1"),
+		URL:      *u,
+		Password: "hunter2",
+		Token:    NewSecret("t0k3n"),
+		Skipped:  "x",
+	}
+	v.Name = "orders"
+	v.LogLevel = "debug"
+	v.Nested.Level = 3
+	return v
+}
+
+func TestMarshal(t *testing.T) {
+	t.Parallel()
+	m, err := Marshal(newMarshalTarget())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]any{
+		"name":      "orders",
+		"log_level": "debug",
+		"host":      "localhost",
+		"port":      int64(8080),
+		"ratio":     0.5,
+		"debug":     true,
+		"timeout":   "1.5s",
+		"start":     "2024-03-01",
+		"tags":      "a;b",
+		"ports":     []any{uint64(80), uint64(443)},
+		"labels":    map[string]any{"team": "core"},
+		"servers":   []any{map[string]any{"host": "s1", "port": int64(81), "timeout": "1s"}},
+		"ip":        "10.0.0.1",
+		"url":       "https://example.com/x",
+		"password":  redactedValue,
+		"token":     redactedValue,
+		"nested":    map[string]any{"level": int64(3)},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("unexpected map:\n got %#v\nwant %#v", m, want)
+	}
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	t.Parallel()
+	src := newMarshalTarget()
+	src.Password, src.Token = "", Secret[string]{}
+
+	m, err := Marshal(&src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	delete(m, "password")
+	delete(m, "token")
+
+	var dst marshalTarget
+	if err = FromMap(m).Unmarshal("", &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	src.Skipped = ""
+	if !reflect.DeepEqual(src, dst) {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", dst, src)
+	}
+}
+
+func TestMarshal_Naming(t *testing.T) {
+	t.Parallel()
+	v := struct {
+		MaxIdleConns int
+	}{MaxIdleConns: 4}
+	m, err := Marshal(v, WithNameMapper(SnakeCase))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m["max_idle_conns"] != int64(4) {
+		t.Errorf("unexpected map: %v", m)
+	}
+}
+
+func TestMarshal_InvalidInput(t *testing.T) {
+	t.Parallel()
+	var nilPtr *marshalTarget
+	for _, v := range []any{42, nilPtr} {
+		if _, err := Marshal(v); err == nil {
+			t.Errorf("expected error for %T", v)
+		}
+	}
+	bad := struct {
+		M map[int]string `cfg:"m"`
+	}{M: map[int]string{1: "a"}}
+	if _, err := Marshal(bad); err == nil {
+		t.Error("expected error for non-string map keys")
+	}
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type Migration struct {
+	Version int
+	Apply   func(values map[string]any) error
+}
+
+func WithAliases(aliases map[string]string) Option {
+	return optionFunc(func(b *builder) {
+		if b.aliases == nil {
+			b.aliases = make(map[string]string, len(aliases))
+		}
+		maps.Copy(b.aliases, aliases)
+	})
+}
+
+func WithMigrations(versionKey string, migrations []Migration) Option {
+	return optionFunc(func(b *builder) {
+		b.versionKey = versionKey
+		b.migrations = append(b.migrations, migrations...)
+	})
+}
+
+func (b *builder) applyAliases(values map[string]any, sources sourceMap) error {
+	warn := b.warningHandler()
+	for _, old := range slices.Sorted(maps.Keys(b.aliases)) {
+		v, ok := lookupPath(values, old)
+		if !ok {
+			continue
+		}
+		target := b.aliases[old]
+
+		leaves := make(map[string]any)
+		flattenValue(old, v, leaves)
+		for _, from := range slices.Sorted(maps.Keys(leaves)) {
+			to := target + strings.TrimPrefix(from, old)
+			if existing, ok := lookupPath(values, to); ok && !sameValue(existing, leaves[from]) {
+				return fmt.Errorf("config: alias %q conflicts with %q: both are set to different values", from, to)
+			}
+		}
+
+		warn(Violation{
+			Key:      old,
+			Code:     CodeDeprecated,
+			Message:  fmt.Sprintf("key is deprecated, use %q instead", target),
+			Source:   sources.lookup(old),
+			Severity: SeverityWarning,
+		})
+
+		deletePath(values, old)
+		for from, val := range leaves {
+			to := target + strings.TrimPrefix(from, old)
+			setNested(values, to, val)
+			if src, ok := sources[from]; ok {
+				delete(sources, from)
+				if _, set := sources[to]; !set {
+					sources[to] = src
+				}
+			}
+		}
+		b.renameSecrets(old, target)
+	}
+	return nil
+}
+
+func (b *builder) renameSecrets(from, to string) {
+	rename := func(key string) string {
+		if key == from || strings.HasPrefix(key, from+".") {
+			return to + strings.TrimPrefix(key, from)
+		}
+		return key
+	}
+
+	for i, key := range b.secrets {
+		b.secrets[i] = rename(key)
+	}
+	for key := range b.literal {
+		if renamed := rename(key); renamed != key {
+			delete(b.literal, key)
+			b.literal[renamed] = true
+		}
+	}
+}
+
+func (b *builder) dropMissingSecrets(values map[string]any) {
+	b.secrets = slices.DeleteFunc(b.secrets, func(key string) bool {
+		_, ok := lookupPath(values, key)
+		return !ok
+	})
+	for key := range b.literal {
+		if _, ok := lookupPath(values, key); !ok {
+			delete(b.literal, key)
+		}
+	}
+}
+
+func sameValue(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	switch a.(type) {
+	case map[string]any, []any:
+		return false
+	}
+	switch b.(type) {
+	case map[string]any, []any:
+		return false
+	}
+	return looseEqual(a, b)
+}
+
+func (b *builder) applyMigrations(values map[string]any) error {
+	if len(b.migrations) == 0 {
+		return nil
+	}
+
+	current := 0
+	if v, ok := lookupPath(values, b.versionKey); ok {
+		n, ok := toInt(v)
+		if !ok {
+			return fmt.Errorf("config: %q must be an integer version, got %v", b.versionKey, v)
+		}
+		current = n
+	}
+
+	sorted := slices.SortedFunc(slices.Values(b.migrations), func(x, y Migration) int {
+		return cmp.Compare(x.Version, y.Version)
+	})
+	for _, m := range sorted {
+		if m.Version <= current {
+			continue
+		}
+		moves, err := recordMoves(values, m.Apply)
+		if err != nil {
+			return fmt.Errorf("config: migration to version %d failed: %w", m.Version, err)
+		}
+		for _, mv := range moves {
+			b.renameSecrets(mv.from, mv.to)
+		}
+		b.dropMissingSecrets(values)
+		current = m.Version
+		setNested(values, b.versionKey, current)
+	}
+	return nil
+}
+
+func MoveKey(from, to string) func(values map[string]any) error {
+	return func(values map[string]any) error {
+		v, ok := lookupPath(values, from)
+		if !ok {
+			return nil
+		}
+		deletePath(values, from)
+		setNested(values, to, v)
+		if rec, ok := moveRecorders.Load(reflect.ValueOf(values).UnsafePointer()); ok {
+			moves := rec.(*[]keyMove)
+			*moves = append(*moves, keyMove{from: from, to: to})
+		}
+		return nil
+	}
+}
+
+type keyMove struct {
+	from, to string
+}
+
+var moveRecorders sync.Map
+
+func recordMoves(values map[string]any, apply func(map[string]any) error) ([]keyMove, error) {
+	var moves []keyMove
+	id := reflect.ValueOf(values).UnsafePointer()
+	moveRecorders.Store(id, &moves)
+	defer moveRecorders.Delete(id)
+	err := apply(values)
+	return moves, err
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNew_WithAliases(t *testing.T) {
+	t.Parallel()
+	var warnings []Violation
+	cfg, err := New(
+		WithLoader(&staticLoader{data: map[string]any{
+			"db":    map[string]any{"host": "old-host", "opts": map[string]any{"ssl": true}},
+			"cache": map[string]any{"ttl": "1m"},
+		}}),
+		WithAliases(map[string]string{"db.host": "database.host", "db.opts": "database.options"}),
+		WithAliases(map[string]string{"cache.ttl": "cache.expiration"}),
+		WithWarningHandler(func(v Violation) { warnings = append(warnings, v) }),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.GetString("database.host") != "old-host" || !cfg.GetBool("database.options.ssl") {
+		t.Errorf("expected values under new keys, got %v", cfg.All())
+	}
+	if cfg.Has("db") || cfg.Has("cache.ttl") || cfg.GetString("cache.expiration") != "1m" {
+		t.Errorf("expected old keys to be removed, got %v", cfg.All())
+	}
+	if cfg.Source("database.host") != "*config.staticLoader" {
+		t.Errorf("expected source to follow the alias, got %q", cfg.Source("database.host"))
+	}
+	if len(warnings) != 3 || warnings[0].Key != "cache.ttl" || warnings[0].Code != CodeDeprecated {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+}
+
+func TestNew_WithAliasesSameValue(t *testing.T) {
+	t.Parallel()
+	cfg, err := New(
+		WithLoader(&staticLoader{data: map[string]any{"old": "8080", "new": 8080}}),
+		WithAliases(map[string]string{"old": "new"}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Has("old") || cfg.GetInt("new") != 8080 {
+		t.Errorf("unexpected values: %v", cfg.All())
+	}
+}
+
+func TestNew_WithAliasesConflict(t *testing.T) {
+	t.Parallel()
+	_, err := New(
+		WithLoader(&staticLoader{data: map[string]any{
+			"db":       map[string]any{"password": "a"},
+			"database": map[string]any{"password": "b"},
+		}}),
+		WithAliases(map[string]string{"db": "database"}),
+	)
+	if err == nil || !strings.Contains(err.Error(), `alias "db.password" conflicts with "database.password"`) {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+	if err != nil && (strings.Contains(err.Error(), `"a"`) || strings.Contains(err.Error(), `"b"`)) {
+		t.Errorf("conflict error leaked values: %v", err)
+	}
+}
+
+func TestNew_WithMigrations(t *testing.T) {
+	t.Parallel()
+	migrations := []Migration{
+		{Version: 3, Apply: func(values map[string]any) error {
+			setNested(values, "server.timeout", "30s")
+			return nil
+		}},
+		{Version: 2, Apply: MoveKey("listen", "server.addr")},
+		{Version: 1, Apply: func(map[string]any) error { return errors.New("must not run") }},
+	}
+	cfg, err := New(
+		WithLoader(&staticLoader{data: map[string]any{"version": 1, "listen": ":8080"}}),
+		WithMigrations("version", migrations),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GetString("server.addr") != ":8080" || cfg.Has("listen") {
+		t.Errorf("expected key to be moved, got %v", cfg.All())
+	}
+	if cfg.GetInt("version") != 3 || cfg.GetString("server.timeout") != "30s" {
+		t.Errorf("expected all migrations applied, got %v", cfg.All())
+	}
+}
+
+func TestNew_WithMigrationsErrors(t *testing.T) {
+	t.Parallel()
+	errBoom := errors.New("boom")
+	_, err := New(
+		WithLoader(&staticLoader{data: map[string]any{}}),
+		WithMigrations("version", []Migration{{Version: 1, Apply: func(map[string]any) error { return errBoom }}}),
+	)
+	if !errors.Is(err, errBoom) || !strings.Contains(err.Error(), "migration to version 1") {
+		t.Errorf("expected migration error, got %v", err)
+	}
+
+	_, err = New(
+		WithLoader(&staticLoader{data: map[string]any{"version": "latest"}}),
+		WithMigrations("version", []Migration{{Version: 1, Apply: MoveKey("a", "b")}}),
+	)
+	if err == nil || !strings.Contains(err.Error(), "integer version") {
+		t.Errorf("expected version error, got %v", err)
+	}
+}
+
+func TestNew_RenamedSecretsStayRedacted(t *testing.T) {
+	t.Parallel()
+	const secret = "s3cr{{et"
+
+	cases := map[string]Option{
+		"alias":    WithAliases(map[string]string{"database": "db"}),
+		"move key": WithMigrations("version", []Migration{{Version: 1, Apply: MoveKey("database", "db")}}),
+		"custom migration": WithMigrations("version", []Migration{{Version: 1, Apply: func(values map[string]any) error {
+			if err := MoveKey("database.password", "db.password")(values); err != nil {
+				return err
+			}
+			return MoveKey("database.host", "db.host")(values)
+		}}}),
+	}
+	for name, opt := range cases {
+		identity, dataKey, wrapped := sopsAgeFixture(t)
+		dir := t.TempDir()
+		p := writeTestFile(t, dir, "secrets.yaml", sopsYAMLDocumentWith(t, dataKey, wrapped, secret))
+
+		cfg, err := New(FromSOPS(p).WithBasePath(dir).WithAgeKey(identity.String()), opt)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if cfg.GetString("db.password") != secret {
+			t.Errorf("%s: expected secret under the new key, got %q", name, cfg.GetString("db.password"))
+		}
+		if !cfg.IsSensitive("db.password") || cfg.IsSensitive("db.host") {
+			t.Errorf("%s: expected only db.password to be sensitive", name)
+		}
+		if strings.Contains(cfg.String(), "s3cr") || strings.Contains(fmt.Sprint(cfg.Redacted()), "s3cr") {
+			t.Errorf("%s: secret leaked: %s", name, cfg.String())
+		}
+	}
+}
+
+func TestNew_MigrationsDoNotGuessSecretsByValue(t *testing.T) {
+	t.Parallel()
+	const secret = "s3cr{{et"
+	identity, dataKey, wrapped := sopsAgeFixture(t)
+	dir := t.TempDir()
+	p := writeTestFile(t, dir, "secrets.yaml", sopsYAMLDocumentWith(t, dataKey, wrapped, secret))
+
+	cfg, err := New(
+		FromSOPS(p).WithBasePath(dir).WithAgeKey(identity.String()),
+		WithLoader(&staticLoader{data: map[string]any{"greeting": "{{ .database.host }}"}}),
+		WithMigrations("version", []Migration{{Version: 1, Apply: func(values map[string]any) error {
+			db := values["database"].(map[string]any)
+			setNested(values, "banner", db["password"])
+			return MoveKey("database.password", "db.password")(values)
+		}}}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.IsSensitive("db.password") || cfg.IsSensitive("database.password") {
+		t.Errorf("expected the moved key to be sensitive")
+	}
+	if cfg.IsSensitive("banner") {
+		t.Errorf("a key sharing the secret value must not become sensitive")
+	}
+	if cfg.GetString("db.password") != secret {
+		t.Errorf("expected the moved secret to stay literal, got %q", cfg.GetString("db.password"))
+	}
+}
+
+func TestDeletePath(t *testing.T) {
+	t.Parallel()
+	m := map[string]any{"a": map[string]any{"b": map[string]any{"c": 1}}, "x": 1}
+	deletePath(m, "a.b.c")
+	deletePath(m, "x.y")
+	if _, ok := m["a"]; ok || m["x"] != 1 {
+		t.Errorf("unexpected map: %v", m)
+	}
+}
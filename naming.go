@@ -0,0 +1,95 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+type NameMapper func(field string) string
+
+var (
+	LowerCase NameMapper = strings.ToLower
+	ExactCase NameMapper = func(field string) string { return field }
+	SnakeCase NameMapper = func(field string) string { return joinWords(splitWords(field), "_") }
+	KebabCase NameMapper = func(field string) string { return joinWords(splitWords(field), "-") }
+	CamelCase NameMapper = camelCase
+)
+
+func (m NameMapper) name(field string) string {
+	if m == nil {
+		return LowerCase(field)
+	}
+	return m(field)
+}
+
+func splitWords(s string) []string {
+	runes := []rune(s)
+
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, curr := runes[i-1], runes[i]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+		switch {
+		case unicode.IsLower(prev) && unicode.IsUpper(curr):
+		case unicode.IsDigit(prev) && unicode.IsUpper(curr):
+		case unicode.IsUpper(prev) && unicode.IsUpper(curr) && nextLower:
+		case curr == '_' || curr == '-':
+			words = append(words, string(runes[start:i]))
+			start = i + 1
+			continue
+		default:
+			continue
+		}
+
+		words = append(words, string(runes[start:i]))
+		start = i
+	}
+
+	return append(words, string(runes[start:]))
+}
+
+func joinWords(words []string, sep string) string {
+	out := make([]string, 0, len(words))
+	for _, w := range words {
+		if w != "" {
+			out = append(out, strings.ToLower(w))
+		}
+	}
+	return strings.Join(out, sep)
+}
+
+func camelCase(field string) string {
+	var b strings.Builder
+	for _, w := range splitWords(field) {
+		if w == "" {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString(strings.ToLower(w))
+			continue
+		}
+		r := []rune(w)
+		b.WriteRune(unicode.ToUpper(r[0]))
+		b.WriteString(string(r[1:]))
+	}
+	return b.String()
+}
+
+func lookupKey(values map[string]any, name string, foldCase bool) (any, bool) {
+	if val, ok := values[name]; ok {
+		return val, true
+	}
+	if !foldCase {
+		return nil, false
+	}
+	for _, k := range slices.Sorted(maps.Keys(values)) {
+		if strings.EqualFold(k, name) {
+			return values[k], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,53 @@
+package config
+
+import "testing"
+
+func TestNameMappers(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		field                      string
+		snake, kebab, camel, lower string
+	}{
+		{"MaxIdleConns", "max_idle_conns", "max-idle-conns", "maxIdleConns", "maxidleconns"},
+		{"HTTPServer", "http_server", "http-server", "httpServer", "httpserver"},
+		{"UserID", "user_id", "user-id", "userID", "userid"},
+		{"ID", "id", "id", "id", "id"},
+		{"Port2Fallback", "port2_fallback", "port2-fallback", "port2Fallback", "port2fallback"},
+		{"Read_Timeout", "read_timeout", "read-timeout", "readTimeout", "read_timeout"},
+		{"name", "name", "name", "name", "name"},
+	}
+	for _, tc := range cases {
+		if got := SnakeCase(tc.field); got != tc.snake {
+			t.Errorf("SnakeCase(%q) = %q, want %q", tc.field, got, tc.snake)
+		}
+		if got := KebabCase(tc.field); got != tc.kebab {
+			t.Errorf("KebabCase(%q) = %q, want %q", tc.field, got, tc.kebab)
+		}
+		if got := CamelCase(tc.field); got != tc.camel {
+			t.Errorf("CamelCase(%q) = %q, want %q", tc.field, got, tc.camel)
+		}
+		if got := NameMapper(nil).name(tc.field); got != tc.lower {
+			t.Errorf("default(%q) = %q, want %q", tc.field, got, tc.lower)
+		}
+	}
+	if ExactCase("MaxIdleConns") != "MaxIdleConns" {
+		t.Error("expected ExactCase to keep the field name")
+	}
+}
+
+func TestLookupKey(t *testing.T) {
+	t.Parallel()
+	values := map[string]any{"Host": "a", "host": "b", "PORT": 1}
+	if v, ok := lookupKey(values, "host", false); !ok || v != "b" {
+		t.Errorf("expected exact match, got %v", v)
+	}
+	if _, ok := lookupKey(values, "port", false); ok {
+		t.Error("expected case-sensitive miss")
+	}
+	if v, ok := lookupKey(values, "port", true); !ok || v != 1 {
+		t.Errorf("expected case-insensitive match, got %v", v)
+	}
+	if _, ok := lookupKey(values, "missing", true); ok {
+		t.Error("expected miss")
+	}
+}
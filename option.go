@@ -11,8 +11,34 @@ type Option interface {
 }
 
 type builder struct {
-	loaders []Loader
-	logger  Logger
+	loaders   []Loader
+	logger    Logger
+	keySrc    keySource
+	sensitive []string
+	secrets   []string
+	literal   map[string]bool
+	structs   []sensitiveStruct
+	naming    NameMapper
+	foldCase  bool
+	rules     []Rule
+	onWarning func(Violation)
+
+	aliases    map[string]string
+	versionKey string
+	migrations []Migration
+}
+
+type sensitiveStruct struct {
+	key string
+	v   any
+}
+
+func (b *builder) structSensitiveKeys() []string {
+	var keys []string
+	for _, s := range b.structs {
+		keys = append(keys, sensitiveKeysOf(s.key, s.v, b.naming)...)
+	}
+	return keys
 }
 
 type optionFunc func(*builder)
@@ -25,12 +51,60 @@ func WithLogger(l Logger) Option {
 	})
 }
 
+func WithWarningHandler(fn func(Violation)) Option {
+	return optionFunc(func(b *builder) {
+		b.onWarning = fn
+	})
+}
+
 func WithLoader(l Loader) Option {
 	return optionFunc(func(b *builder) {
 		b.loaders = append(b.loaders, l)
 	})
 }
 
+func WithDecryptionKey(key []byte) Option {
+	return optionFunc(func(b *builder) {
+		b.keySrc = staticKey(key)
+	})
+}
+
+func WithDecryptionKeyFromEnv(envVar string) Option {
+	return optionFunc(func(b *builder) {
+		b.keySrc = keyFromEnv(envVar)
+	})
+}
+
+func WithDecryptionKeyFile(path string) Option {
+	return optionFunc(func(b *builder) {
+		b.keySrc = keyFromFile(path)
+	})
+}
+
+func WithSensitiveKeys(patterns ...string) Option {
+	return optionFunc(func(b *builder) {
+		b.sensitive = append(b.sensitive, patterns...)
+	})
+}
+
+func WithSensitiveStruct(key string, v any) Option {
+	return optionFunc(func(b *builder) {
+		b.structs = append(b.structs, sensitiveStruct{key: key, v: v})
+	})
+}
+
+func WithKeyNaming(m NameMapper) Option {
+	return optionFunc(func(b *builder) {
+		b.naming = m
+	})
+}
+
+func WithCaseInsensitiveKeys() Option {
+	return optionFunc(func(b *builder) {
+		b.foldCase = true
+	})
+}
+
 func WithProfile(basePath string, profile string) Option {
 	return optionFunc(func(b *builder) {
 		ext := filepath.Ext(basePath)
@@ -87,3 +161,9 @@ func profileLoaders(ext, basePath, profilePath string) (Loader, Loader) {
 type nopLoader struct{}
 
 func (nopLoader) Load() (map[string]any, error) { return make(map[string]any), nil }
+
+func WithSchema(schema []byte, opts ...SchemaOption) Option {
+	return optionFunc(func(b *builder) {
+		b.rules = append(b.rules, MatchesSchema(schema, opts...))
+	})
+}
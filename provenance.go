@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var indexSegment = regexp.MustCompile(`\[\d+\]`)
+
+type namedSource interface {
+	sourceName() string
+}
+
+func loaderName(l Loader) string {
+	switch src := l.(type) {
+	case namedSource:
+		if name := src.sourceName(); name != "" {
+			return name
+		}
+	case fmt.Stringer:
+		return src.String()
+	}
+	return fmt.Sprintf("%T", l)
+}
+
+type sourceMap map[string]string
+
+func (s sourceMap) record(values map[string]any, name string) {
+	leaves := make(map[string]any)
+	flattenValue("", values, leaves)
+
+	for leaf := range leaves {
+		if leaf == "" {
+			continue
+		}
+		for p := leaf; ; {
+			i := strings.LastIndex(p, ".")
+			if i < 0 {
+				break
+			}
+			if _, err := strconv.Atoi(p[i+1:]); err == nil {
+				p = p[:i]
+				continue
+			}
+			p = p[:i]
+			delete(s, p)
+		}
+		s[leaf] = name
+	}
+}
+
+func (s sourceMap) lookup(key string) string {
+	key = indexSegment.ReplaceAllStringFunc(key, func(seg string) string {
+		return "." + strings.Trim(seg, "[]")
+	})
+	for p := key; p != ""; {
+		if src, ok := s[p]; ok {
+			return src
+		}
+		i := strings.LastIndex(p, ".")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+	return ""
+}
+
+func (s sourceMap) sub(key string) sourceMap {
+	if s == nil {
+		return nil
+	}
+	prefix := key + "."
+	out := make(sourceMap)
+	for k, src := range s {
+		if strings.HasPrefix(k, prefix) {
+			out[strings.TrimPrefix(k, prefix)] = src
+		}
+	}
+	return out
+}
+
+func (s sourceMap) clone() sourceMap {
+	out := make(sourceMap, len(s))
+	for k, src := range s {
+		out[k] = src
+	}
+	return out
+}
+
+func (c *Config) Source(key string) string {
+	return c.sources.lookup(key)
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+type namedLoader struct {
+	staticLoader
+	name string
+}
+
+func (l *namedLoader) String() string { return l.name }
+
+func TestNew_RecordsSources(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	p := writeTestFile(t, dir, "config.yaml", "server:\n  host: localhost\n  port: 8080\nservers:\n  - host: a\n")
+
+	cfg, err := New(
+		WithLoader(FromYAML(p).WithBasePath(dir)),
+		WithLoader(&namedLoader{staticLoader: staticLoader{data: map[string]any{"server": map[string]any{"port": 9090}}}, name: "vault"}),
+		WithLoader(&staticLoader{data: map[string]any{"debug": true}}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]string{
+		"server.host":     p,
+		"server.port":     "vault",
+		"servers[0].host": p,
+		"debug":           fmt.Sprintf("%T", &staticLoader{}),
+		"missing":         "",
+	}
+	for key, want := range cases {
+		if got := cfg.Source(key); got != want {
+			t.Errorf("Source(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestConfig_SourceAfterOverridesAndSub(t *testing.T) {
+	t.Cleanup(func() { os.Unsetenv("PROVTEST_DB__HOST") })
+	os.Setenv("PROVTEST_DB__HOST", "env-host")
+	cfg, err := New(WithLoader(FromEnv("PROVTEST_")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.Source("db.host"); got != "env:PROVTEST_" {
+		t.Errorf("unexpected env source %q", got)
+	}
+
+	over := cfg.WithOverrides(map[string]any{"db.port": 1})
+	if over.Source("db.port") != "overrides" || over.Source("db.host") != "env:PROVTEST_" {
+		t.Errorf("unexpected override sources: %q %q", over.Source("db.port"), over.Source("db.host"))
+	}
+	if cfg.Source("db.port") != "" {
+		t.Error("expected overrides not to leak into the original config")
+	}
+
+	sub, _ := over.GetSub("db")
+	if got := sub.(*Config).Source("host"); got != "env:PROVTEST_" {
+		t.Errorf("unexpected sub source %q", got)
+	}
+}
+
+func TestSourceMap_RecordReplacesParent(t *testing.T) {
+	t.Parallel()
+	s := make(sourceMap)
+	s.record(map[string]any{"a": "scalar"}, "first")
+	s.record(map[string]any{"a": map[string]any{"b": 1}}, "second")
+	if s.lookup("a") != "" || s.lookup("a.b") != "second" {
+		t.Errorf("unexpected sources: %v", s)
+	}
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+type sensitiveKeys struct {
+	patterns []string
+	keys     map[string]struct{}
+	prefix   string
+}
+
+func newSensitiveKeys(patterns []string, keys []string) *sensitiveKeys {
+	if len(patterns) == 0 && len(keys) == 0 {
+		return nil
+	}
+	s := &sensitiveKeys{
+		patterns: patterns,
+		keys:     make(map[string]struct{}, len(keys)),
+	}
+	for _, k := range keys {
+		s.keys[k] = struct{}{}
+	}
+	return s
+}
+
+func (s *sensitiveKeys) matches(key string) bool {
+	if s == nil {
+		return false
+	}
+
+	full := key
+	if s.prefix != "" {
+		full = s.prefix + "." + key
+	}
+
+	if plain := indexSegment.ReplaceAllString(full, ""); plain != full && s.matchesPath(plain) {
+		return true
+	}
+	return s.matchesPath(full)
+}
+
+func (s *sensitiveKeys) matchesPath(full string) bool {
+	for p := full; p != ""; {
+		if s.matchesExact(p) {
+			return true
+		}
+		i := strings.LastIndex(p, ".")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+
+	return false
+}
+
+func (s *sensitiveKeys) matchesExact(key string) bool {
+	if _, ok := s.keys[key]; ok {
+		return true
+	}
+	for _, pattern := range s.patterns {
+		if ok, err := path.Match(pattern, key); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *sensitiveKeys) sub(key string) *sensitiveKeys {
+	if s == nil {
+		return nil
+	}
+	prefix := key
+	if s.prefix != "" {
+		prefix = s.prefix + "." + key
+	}
+	return &sensitiveKeys{patterns: s.patterns, keys: s.keys, prefix: prefix}
+}
+
+func (s *sensitiveKeys) redactMap(m map[string]any, parent string) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		key := k
+		if parent != "" {
+			key = parent + "." + k
+		}
+		out[k] = s.redactValue(v, key)
+	}
+	return out
+}
+
+func (s *sensitiveKeys) redactValue(v any, key string) any {
+	if s.matches(key) {
+		return redactedValue
+	}
+	switch val := v.(type) {
+	case map[string]any:
+		return s.redactMap(val, key)
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = s.redactValue(item, key)
+		}
+		return out
+	default:
+		return deepCopyValue(v)
+	}
+}
+
+func (c *Config) Redacted() map[string]any {
+	return c.sensitive.redactMap(c.values, "")
+}
+
+func (c *Config) IsSensitive(key string) bool {
+	return c.sensitive.matches(key)
+}
+
+func (c *Config) String() string {
+	return fmt.Sprintf("%v", c.Redacted())
+}
+
+func (c *Config) GoString() string {
+	return fmt.Sprintf("&config.Config{values:%#v}", c.Redacted())
+}
+
+func sensitiveKeysOf(key string, v any, naming NameMapper) []string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return collectSensitiveFields(t, key, naming, map[reflect.Type]bool{})
+}
+
+func collectSensitiveFields(t reflect.Type, parent string, naming NameMapper, seen map[reflect.Type]bool) []string {
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	var keys []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag, ok := parseFieldTag(field, naming)
+		if !ok {
+			continue
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if tag.squash {
+			keys = append(keys, collectSensitiveFields(ft, parent, naming, seen)...)
+			continue
+		}
+
+		key := tag.name
+		if parent != "" {
+			key = parent + "." + tag.name
+		}
+
+		if field.Tag.Get("secret") == "true" || isSecretType(ft) {
+			keys = append(keys, key)
+			continue
+		}
+		if et, elemKey := elemStructType(ft, key); et != nil {
+			keys = append(keys, collectSensitiveFields(et, elemKey, naming, seen)...)
+		}
+	}
+
+	return keys
+}
+
+func elemStructType(t reflect.Type, key string) (reflect.Type, string) {
+	for {
+		switch {
+		case t.Kind() == reflect.Pointer:
+			t = t.Elem()
+		case t.Kind() == reflect.Slice && !hasCustomDecoder(t):
+			t = t.Elem()
+		case t.Kind() == reflect.Map:
+			t = t.Elem()
+			key += ".*"
+		case isNestedStruct(t):
+			return t, key
+		default:
+			return nil, ""
+		}
+	}
+}
+
+func trimIndex(key string) string {
+	if i := strings.IndexByte(key, '['); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+type redactedError struct {
+	err error
+}
+
+func (e redactedError) Error() string {
+	return "sensitive value cannot be decoded"
+}
+
+func (e redactedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,347 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSensitiveKeys_Matches(t *testing.T) {
+	t.Parallel()
+	s := newSensitiveKeys([]string{"*.password", "token"}, []string{"db.dsn"})
+	cases := map[string]bool{
+		"db.password":         true,
+		"a.b.password":        true,
+		"password":            false,
+		"token":               true,
+		"token.inner":         true,
+		"db.dsn":              true,
+		"db.host":             false,
+		"api.token.something": false,
+	}
+	for key, want := range cases {
+		if got := s.matches(key); got != want {
+			t.Errorf("matches(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestSensitiveKeys_Nil(t *testing.T) {
+	t.Parallel()
+	if newSensitiveKeys(nil, nil) != nil {
+		t.Fatal("expected nil for empty input")
+	}
+	var s *sensitiveKeys
+	if s.matches("x") || s.sub("x") != nil {
+		t.Error("expected nil-safe methods")
+	}
+}
+
+func TestSensitiveKeys_InvalidPattern(t *testing.T) {
+	t.Parallel()
+	s := newSensitiveKeys([]string{"[invalid"}, nil)
+	if s.matches("x") {
+		t.Error("expected invalid pattern to never match")
+	}
+}
+
+func newSensitiveConfig() *Config {
+	return &Config{
+		values: map[string]any{
+			"db": map[string]any{
+				"host":     "localhost",
+				"password": "hunter2",
+				"replica":  map[string]any{"password": "hunter3"},
+			},
+			"api": map[string]any{"token": "abc"},
+		},
+		sensitive: newSensitiveKeys([]string{"*.password", "api.token"}, nil),
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	t.Parallel()
+	cfg := newSensitiveConfig()
+	r := cfg.Redacted()
+	db := r["db"].(map[string]any)
+	if db["password"] != redactedValue || db["host"] != "localhost" {
+		t.Errorf("unexpected db: %v", db)
+	}
+	if db["replica"].(map[string]any)["password"] != redactedValue {
+		t.Errorf("expected nested password redacted, got %v", db["replica"])
+	}
+	if r["api"].(map[string]any)["token"] != redactedValue {
+		t.Errorf("expected token redacted, got %v", r["api"])
+	}
+	if cfg.GetString("db.password") != "hunter2" {
+		t.Error("expected original value to stay accessible")
+	}
+}
+
+func TestConfig_FmtIsRedacted(t *testing.T) {
+	t.Parallel()
+	cfg := newSensitiveConfig()
+	for _, verb := range []string{"%v", "%+v", "%s", "%#v"} {
+		out := fmt.Sprintf(verb, cfg)
+		if strings.Contains(out, "hunter2") || strings.Contains(out, "abc") {
+			t.Errorf("%s leaked a secret: %s", verb, out)
+		}
+		if !strings.Contains(out, redactedValue) {
+			t.Errorf("%s: expected redaction marker, got %s", verb, out)
+		}
+	}
+}
+
+func TestConfig_IsSensitive(t *testing.T) {
+	t.Parallel()
+	cfg := newSensitiveConfig()
+	if !cfg.IsSensitive("db.password") || cfg.IsSensitive("db.host") {
+		t.Error("unexpected sensitivity")
+	}
+}
+
+func TestConfig_GetSubKeepsSensitivity(t *testing.T) {
+	t.Parallel()
+	cfg := newSensitiveConfig()
+	sub, ok := cfg.GetSub("api")
+	if !ok {
+		t.Fatal("expected sub")
+	}
+	s := fmt.Sprint(sub)
+	if strings.Contains(s, "abc") {
+		t.Errorf("sub config leaked token: %s", s)
+	}
+}
+
+func TestConfig_WithOverridesKeepsSensitivity(t *testing.T) {
+	t.Parallel()
+	cfg := newSensitiveConfig().WithOverrides(map[string]any{"db.password": "new"})
+	if cfg.Redacted()["db"].(map[string]any)["password"] != redactedValue {
+		t.Error("expected overridden password to stay redacted")
+	}
+}
+
+func TestNew_WithSensitiveKeys(t *testing.T) {
+	t.Parallel()
+	cfg, err := New(
+		WithLoader(&staticLoader{data: map[string]any{"smtp": map[string]any{"password": "p"}}}),
+		WithSensitiveKeys("*.password"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.IsSensitive("smtp.password") {
+		t.Error("expected smtp.password to be sensitive")
+	}
+}
+
+type secretTagged struct {
+	User     string `cfg:"user"`
+	Password string `cfg:"password" secret:"true"`
+	TLS      struct {
+		Key string `cfg:"key" secret:"true"`
+	} `cfg:"tls"`
+	Ignored string `cfg:"-" secret:"true"`
+}
+
+func TestNew_WithSensitiveStruct(t *testing.T) {
+	t.Parallel()
+	cfg, err := New(WithSensitiveStruct("db", &secretTagged{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.IsSensitive("db.password") || !cfg.IsSensitive("db.tls.key") {
+		t.Error("expected tagged fields to be sensitive")
+	}
+	if cfg.IsSensitive("db.user") {
+		t.Error("expected untagged field to be visible")
+	}
+}
+
+func TestSensitiveKeysOf_NotStruct(t *testing.T) {
+	t.Parallel()
+	if keys := sensitiveKeysOf("", 42, nil); keys != nil {
+		t.Errorf("expected nil, got %v", keys)
+	}
+}
+
+func TestNew_DecryptedValuesAreSensitive(t *testing.T) {
+	t.Parallel()
+	key := testKey(t)
+	enc, _ := Encrypt("hunter2", key)
+	cfg, err := New(
+		WithLoader(&staticLoader{data: map[string]any{"db": map[string]any{"password": enc}, "list": []any{enc}}}),
+		WithDecryptionKey(key),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.IsSensitive("db.password") || !cfg.IsSensitive("list") {
+		t.Error("expected decrypted keys to be sensitive")
+	}
+	if strings.Contains(cfg.String(), "hunter2") {
+		t.Error("String leaked decrypted value")
+	}
+}
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Debug(msg string, args ...any) {
+	l.lines = append(l.lines, fmt.Sprint(append([]any{msg}, args...)...))
+}
+
+func TestNew_LoggerDoesNotLeakValues(t *testing.T) {
+	t.Parallel()
+	logger := &recordingLogger{}
+	_, err := New(
+		WithLogger(logger),
+		WithLoader(&staticLoader{data: map[string]any{"password": "hunter2"}}),
+		WithSensitiveKeys("password"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range logger.lines {
+		if strings.Contains(line, "hunter2") {
+			t.Errorf("logger leaked value: %s", line)
+		}
+	}
+}
+
+func TestNew_ParseErrorDoesNotLeakValues(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	p := writeTestFile(t, dir, "config.yaml", "db:\n  password: hunter2\n  host: [a\n")
+
+	logger := &recordingLogger{}
+	_, err := New(WithLogger(logger), WithLoader(FromYAML(p).WithBasePath(dir)))
+	if !errors.Is(err, ErrParseYAML) || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("expected parse error without values, got %v", err)
+	}
+	for _, line := range logger.lines {
+		if strings.Contains(line, "hunter2") {
+			t.Errorf("logger leaked value: %s", line)
+		}
+	}
+}
+
+func TestTrimIndex(t *testing.T) {
+	t.Parallel()
+	if trimIndex("a.b[0]") != "a.b" || trimIndex("a.b") != "a.b" {
+		t.Error("unexpected trimIndex result")
+	}
+}
+
+func TestConfig_RedactedInsideLists(t *testing.T) {
+	t.Parallel()
+	cfg := &Config{
+		values: map[string]any{
+			"servers": []any{map[string]any{"host": "a", "password": "hunter2"}},
+		},
+		sensitive: newSensitiveKeys([]string{"*.password"}, nil),
+	}
+	item := cfg.Redacted()["servers"].([]any)[0].(map[string]any)
+	if item["password"] != redactedValue || item["host"] != "a" {
+		t.Errorf("unexpected list item: %v", item)
+	}
+}
+
+func TestNew_SecretsInsideListsStayHidden(t *testing.T) {
+	t.Parallel()
+	type server struct {
+		Host     string `cfg:"host"`
+		Password string `cfg:"password" validate:"regex=^x"`
+	}
+	type target struct {
+		Servers []server `cfg:"servers"`
+	}
+
+	cfg, err := New(
+		WithLoader(&staticLoader{data: map[string]any{
+			"servers": []any{map[string]any{"host": "a", "password": "hunter2"}},
+		}}),
+		WithSensitiveStruct("servers", secretTagged{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.IsSensitive("servers[0].password") || cfg.IsSensitive("servers[0].host") {
+		t.Error("expected indexed password to be sensitive")
+	}
+
+	err = cfg.Validate(Each("servers", MatchRegex("password", "^x")))
+	if err == nil || strings.Contains(err.Error(), "hunter2") || !strings.Contains(err.Error(), "servers[0].password") {
+		t.Errorf("expected redacted violation, got %v", err)
+	}
+
+	var out target
+	err = cfg.Unmarshal("", &out)
+	if err == nil || strings.Contains(err.Error(), "hunter2") || !strings.Contains(err.Error(), "servers[0].password") {
+		t.Errorf("expected redacted unmarshal violation, got %v", err)
+	}
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		for _, d := range ve.Details {
+			if d.Value == "hunter2" {
+				t.Errorf("violation leaked value: %+v", d)
+			}
+		}
+	}
+}
+
+type secretCollections struct {
+	Servers   []secretTagged            `cfg:"servers"`
+	Upstreams map[string]*secretTagged  `cfg:"upstreams"`
+	Self      []secretCollections       `cfg:"self"`
+	IPs       map[string][]secretTagged `cfg:"ips"`
+}
+
+func TestSensitiveKeysOf_Collections(t *testing.T) {
+	t.Parallel()
+	keys := sensitiveKeysOf("app", secretCollections{}, nil)
+	want := map[string]bool{
+		"app.servers.password":     true,
+		"app.upstreams.*.password": true,
+		"app.ips.*.tls.key":        true,
+	}
+	for _, k := range keys {
+		delete(want, k)
+	}
+	if len(want) != 0 {
+		t.Errorf("missing keys %v in %v", want, keys)
+	}
+}
+
+type embeddedSecrets struct {
+	secretTagged
+	Token string `cfg:"token" secret:"true"`
+}
+
+func TestSensitiveKeysOf_Embedded(t *testing.T) {
+	t.Parallel()
+	keys := sensitiveKeysOf("svc", embeddedSecrets{}, nil)
+	want := map[string]bool{"svc.password": true, "svc.tls.key": true, "svc.token": true}
+	for _, k := range keys {
+		delete(want, k)
+	}
+	if len(want) != 0 {
+		t.Errorf("missing keys %v in %v", want, keys)
+	}
+}
+
+func TestNew_WithSensitiveStructUsesKeyNaming(t *testing.T) {
+	t.Parallel()
+	type creds struct {
+		APIKey string `secret:"true"`
+	}
+	cfg, err := New(WithSensitiveStruct("svc", creds{}), WithKeyNaming(SnakeCase))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.IsSensitive("svc.api_key") {
+		t.Error("expected snake_case key to be sensitive")
+	}
+}
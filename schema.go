@@ -0,0 +1,465 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+const durationPattern = `^-?([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$|^0$`
+
+type SchemaTypes []string
+
+func (t SchemaTypes) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *SchemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = SchemaTypes{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Type                 SchemaTypes        `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+
+	Bool *bool `json:"-"`
+}
+
+type plainSchema Schema
+
+func (s Schema) MarshalJSON() ([]byte, error) {
+	if s.Bool != nil {
+		return json.Marshal(*s.Bool)
+	}
+	return json.Marshal(plainSchema(s))
+}
+
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*s = Schema{Bool: &b}
+		return nil
+	}
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return err
+	}
+	for _, k := range slices.Sorted(maps.Keys(keywords)) {
+		if !schemaKeywords[k] {
+			return fmt.Errorf("unsupported keyword %q", k)
+		}
+	}
+	var plain plainSchema
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return err
+	}
+	*s = Schema(plain)
+	return nil
+}
+
+var schemaKeywords = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"format": true, "default": true, "examples": true,
+	"type": true, "enum": true, "pattern": true, "minimum": true, "maximum": true,
+	"properties": true, "required": true, "items": true, "additionalProperties": true,
+}
+
+func SchemaFor[T any](opts ...EncodeOption) *Schema {
+	o := newEncodeOptions(opts)
+	g := &schemaGenerator{naming: o.naming, seen: make(map[reflect.Type]bool)}
+
+	s := g.schemaOf(reflect.TypeFor[T](), "")
+	s.Schema = schemaDialect
+	return s
+}
+
+type schemaGenerator struct {
+	naming NameMapper
+	seen   map[reflect.Type]bool
+}
+
+func (g *schemaGenerator) schemaOf(t reflect.Type, tag reflect.StructTag) *Schema {
+	if t.Kind() == reflect.Pointer {
+		s := g.schemaOf(t.Elem(), tag)
+		if len(s.Type) > 0 {
+			s.Type = append(s.Type, "null")
+		}
+		return s
+	}
+
+	if isSecretType(t) {
+		return g.schemaOf(reflect.Zero(reflect.PointerTo(t)).MethodByName("Reveal").Type().Out(0), tag)
+	}
+
+	switch {
+	case t == durationType:
+		return &Schema{Type: SchemaTypes{"string", "integer"}, Pattern: durationPattern}
+	case t == timeType:
+		return timeSchema(tag.Get("layout"))
+	case isNestedStruct(t):
+		return g.objectSchema(t)
+	case hasCustomDecoder(t):
+		return &Schema{Type: SchemaTypes{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: SchemaTypes{"string"}}
+	case reflect.Bool:
+		return &Schema{Type: SchemaTypes{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: SchemaTypes{"integer"}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: SchemaTypes{"integer"}, Minimum: schemaBound(0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: SchemaTypes{"number"}}
+	case reflect.Slice:
+		return &Schema{Type: SchemaTypes{"array", "string"}, Items: g.schemaOf(t.Elem(), "")}
+	case reflect.Array:
+		return &Schema{Type: SchemaTypes{"array"}, Items: g.schemaOf(t.Elem(), "")}
+	case reflect.Map:
+		return &Schema{Type: SchemaTypes{"object"}, AdditionalProperties: g.schemaOf(t.Elem(), "")}
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *schemaGenerator) objectSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: SchemaTypes{"object"}}
+	if g.seen[t] {
+		return s
+	}
+	g.seen[t] = true
+	defer delete(g.seen, t)
+
+	s.Properties = make(map[string]*Schema)
+	g.addFields(s, t)
+	return s
+}
+
+func (g *schemaGenerator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag, ok := parseFieldTag(field, g.naming)
+		if !ok {
+			continue
+		}
+
+		if tag.squash {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			g.addFields(s, ft)
+			continue
+		}
+
+		prop := g.schemaOf(field.Type, field.Tag)
+		applyValidateTag(prop, field.Tag.Get("validate"), field.Type)
+		if def, ok := field.Tag.Lookup("default"); ok {
+			prop.Default = schemaDefault(def, field.Type, field.Tag)
+		}
+
+		s.Properties[tag.name] = prop
+		if tag.has("required") {
+			s.Required = append(s.Required, tag.name)
+		}
+	}
+}
+
+func timeSchema(layout string) *Schema {
+	s := &Schema{Type: SchemaTypes{"string"}}
+	switch layout {
+	case "", time.RFC3339, time.RFC3339Nano:
+		s.Format = "date-time"
+	case time.DateOnly:
+		s.Format = "date"
+	case time.TimeOnly:
+		s.Format = "time"
+	default:
+		s.Description = "time in layout " + layout
+	}
+	return s
+}
+
+func applyValidateTag(s *Schema, spec string, t reflect.Type) {
+	for spec != "" {
+		var part string
+		if strings.HasPrefix(spec, "regex=") {
+			part, spec = spec, ""
+		} else {
+			part, spec, _ = strings.Cut(spec, ",")
+		}
+
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "min", "max":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			if name == "min" {
+				s.Minimum = schemaBound(n)
+			} else {
+				s.Maximum = schemaBound(n)
+			}
+		case "oneof":
+			for _, v := range strings.Fields(arg) {
+				s.Enum = append(s.Enum, schemaDefault(v, t, ""))
+			}
+		case "regex":
+			s.Pattern = arg
+		}
+	}
+}
+
+func schemaDefault(def string, t reflect.Type, tag reflect.StructTag) any {
+	v, err := (&decoder{}).parseStringToType(def, t, tag)
+	if err != nil {
+		return def
+	}
+	out, err := marshalValue(v, tag, "", nil)
+	if err != nil {
+		return def
+	}
+	return out
+}
+
+func schemaBound(n float64) *float64 {
+	if math.IsNaN(n) {
+		return nil
+	}
+	return &n
+}
+
+type SchemaOption interface {
+	applySchema(o *schemaOptions)
+}
+
+type schemaOptions struct {
+	coerce bool
+}
+
+type schemaOptionFunc func(*schemaOptions)
+
+func (f schemaOptionFunc) applySchema(o *schemaOptions) { f(o) }
+
+func WithTypeCoercion() SchemaOption {
+	return schemaOptionFunc(func(o *schemaOptions) {
+		o.coerce = true
+	})
+}
+
+func MatchesSchema(schema []byte, opts ...SchemaOption) Rule {
+	var s Schema
+	err := json.Unmarshal(schema, &s)
+
+	o := &schemaOptions{}
+	for _, opt := range opts {
+		opt.applySchema(o)
+	}
+
+	return func(c *Config) error {
+		if err != nil {
+			return fmt.Errorf("config: invalid schema: %w", err)
+		}
+
+		v := &schemaValidator{coerce: o.coerce}
+		v.validate(&s, c.values, "")
+		if len(v.errs) > 0 {
+			return newValidationError(v.errs)
+		}
+		return nil
+	}
+}
+
+type schemaValidator struct {
+	errs   []error
+	coerce bool
+}
+
+func (v *schemaValidator) validate(s *Schema, val any, key string) {
+	if s.Bool != nil {
+		if !*s.Bool {
+			v.errs = append(v.errs, Violation{Key: key, Code: CodeUnknown, Value: val, Message: "key is not allowed"})
+		}
+		return
+	}
+
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return schemaTypeMatches(t, val, v.coerce) }) {
+		v.errs = append(v.errs, Violation{
+			Key:     key,
+			Code:    CodeType,
+			Value:   val,
+			Message: fmt.Sprintf("expected %s, got %s", strings.Join(s.Type, " or "), schemaTypeOf(val)),
+		})
+		return
+	}
+
+	v.errs = append(v.errs, v.check(s, val, key)...)
+
+	switch val := val.(type) {
+	case map[string]any:
+		v.validateObject(s, val, key)
+	case []any:
+		if s.Items != nil {
+			for i, item := range val {
+				v.validate(s.Items, item, indexKey(key, i))
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) check(s *Schema, val any, key string) []error {
+	var errs []error
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return v.equal(e, val) }) {
+		errs = append(errs, Violation{Key: key, Code: CodeEnum, Value: val, Message: fmt.Sprintf("value %v is not one of %v", val, s.Enum)})
+	}
+
+	if s.Pattern != "" {
+		if _, ok := val.(string); ok {
+			errs = append(errs, checkRegex(key, val, s.Pattern))
+		}
+	}
+
+	if s.Minimum != nil || s.Maximum != nil {
+		if _, ok := toFloat64(val); ok && (v.coerce || isNumber(val)) {
+			lo, hi := math.Inf(-1), math.Inf(1)
+			if s.Minimum != nil {
+				lo = *s.Minimum
+			}
+			if s.Maximum != nil {
+				hi = *s.Maximum
+			}
+			errs = append(errs, checkRange(key, val, lo, hi))
+		}
+	}
+
+	return slices.DeleteFunc(errs, func(err error) bool { return err == nil })
+}
+
+func (v *schemaValidator) validateObject(s *Schema, m map[string]any, key string) {
+	for _, name := range s.Required {
+		if _, ok := m[name]; !ok {
+			v.errs = append(v.errs, missingKeyError(joinKey(key, name)))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(m)) {
+		if prop, ok := s.Properties[name]; ok {
+			v.validate(prop, m[name], joinKey(key, name))
+			continue
+		}
+		if s.AdditionalProperties != nil {
+			v.validate(s.AdditionalProperties, m[name], joinKey(key, name))
+		}
+	}
+}
+
+func (v *schemaValidator) equal(want, got any) bool {
+	if v.coerce {
+		return looseEqual(want, got)
+	}
+	return diffEqual(want, got, false)
+}
+
+func schemaTypeMatches(t string, val any, coerce bool) bool {
+	if s, ok := val.(string); ok && coerce && t != "string" {
+		return schemaStringMatches(t, s)
+	}
+
+	switch t {
+	case "null":
+		return val == nil
+	case "boolean":
+		_, ok := val.(bool)
+		return ok
+	case "integer":
+		f, ok := toFloat64(val)
+		return ok && isNumber(val) && f == math.Trunc(f)
+	case "number":
+		return isNumber(val)
+	case "string":
+		_, ok := val.(string)
+		return ok || coerce && (isNumber(val) || schemaTypeMatches("boolean", val, false))
+	case "array":
+		_, ok := val.([]any)
+		return ok
+	case "object":
+		_, ok := val.(map[string]any)
+		return ok
+	default:
+		return false
+	}
+}
+
+func schemaStringMatches(t, s string) bool {
+	switch t {
+	case "boolean":
+		_, ok := toBool(s)
+		return ok
+	case "integer":
+		f, ok := toFloat64(s)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := toFloat64(s)
+		return ok
+	default:
+		return false
+	}
+}
+
+func schemaTypeOf(val any) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", val)
+	}
+}
@@ -0,0 +1,325 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaTarget struct {
+	Server struct {
+		Host string `cfg:"host,required"`
+		Port int    `cfg:"port" default:"8080" validate:"min=1,max=65535"`
+	} `cfg:"server,required"`
+	Level    string            `cfg:"level" validate:"oneof=debug info" default:"info"`
+	Workers  uint              `cfg:"workers" validate:"oneof=1 2 4"`
+	Timeout  time.Duration     `cfg:"timeout" default:"5s"`
+	Start    time.Time         `cfg:"start" layout:"2006-01-02"`
+	Backup   *upstreamTarget   `cfg:"backup"`
+	Tags     []string          `cfg:"tags"`
+	Labels   map[string]string `cfg:"labels"`
+	Password Secret[string]    `cfg:"password"`
+	Skip     string            `cfg:"-"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	t.Parallel()
+	s := SchemaFor[schemaTarget]()
+	if s.Schema != schemaDialect || !slices.Equal(s.Type, SchemaTypes{"object"}) {
+		t.Fatalf("unexpected root: %+v", s)
+	}
+	if !slices.Equal(s.Required, []string{"server"}) {
+		t.Errorf("unexpected required: %v", s.Required)
+	}
+	if _, ok := s.Properties["skip"]; ok {
+		t.Error("expected skipped field to be absent")
+	}
+
+	port := s.Properties["server"].Properties["port"]
+	if *port.Minimum != 1 || *port.Maximum != 65535 || port.Default != int64(8080) {
+		t.Errorf("unexpected port schema: %+v", port)
+	}
+	if level := s.Properties["level"]; len(level.Enum) != 2 || level.Default != "info" {
+		t.Errorf("unexpected level schema: %+v", level)
+	}
+	if workers := s.Properties["workers"]; workers.Enum[2] != uint64(4) || *workers.Minimum != 0 {
+		t.Errorf("unexpected workers schema: %+v", workers)
+	}
+	if timeout := s.Properties["timeout"]; timeout.Default != "5s" || timeout.Pattern == "" {
+		t.Errorf("unexpected timeout schema: %+v", timeout)
+	}
+	if start := s.Properties["start"]; start.Format != "date" {
+		t.Errorf("unexpected start schema: %+v", start)
+	}
+	if backup := s.Properties["backup"]; !slices.Equal(backup.Type, SchemaTypes{"object", "null"}) {
+		t.Errorf("expected nullable backup, got %v", backup.Type)
+	}
+	if tags := s.Properties["tags"]; !slices.Equal(tags.Type, SchemaTypes{"array", "string"}) || tags.Items == nil || tags.Items.Type[0] != "string" {
+		t.Errorf("unexpected tags schema: %+v", tags)
+	}
+	if labels := s.Properties["labels"]; labels.AdditionalProperties.Type[0] != "string" {
+		t.Errorf("unexpected labels schema: %+v", labels)
+	}
+	if password := s.Properties["password"]; password.Type[0] != "string" {
+		t.Errorf("unexpected password schema: %+v", password)
+	}
+}
+
+func TestSchemaFor_SeparatedSlices(t *testing.T) {
+	t.Parallel()
+	type target struct {
+		Tags  []string `cfg:"tags" separator:";"`
+		Ports []int    `cfg:"ports"`
+	}
+	data, err := json.Marshal(SchemaFor[target]())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg := newTestConfig(map[string]any{"tags": "a;b", "ports": []any{80, 443}})
+	if err := cfg.Validate(MatchesSchema(data)); err != nil {
+		t.Errorf("expected separated string to match the generated schema, got %v", err)
+	}
+	var dst target
+	if err := cfg.Unmarshal("", &dst); err != nil || !slices.Equal(dst.Tags, []string{"a", "b"}) {
+		t.Errorf("unexpected unmarshal result: %v, %v", dst.Tags, err)
+	}
+}
+
+func TestSchemaFor_NameMapper(t *testing.T) {
+	t.Parallel()
+	type target struct {
+		MaxConns int
+	}
+	s := SchemaFor[target](WithNameMapper(SnakeCase))
+	if _, ok := s.Properties["max_conns"]; !ok {
+		t.Errorf("expected snake_case property, got %v", s.Properties)
+	}
+}
+
+func TestSchemaFor_Recursive(t *testing.T) {
+	t.Parallel()
+	s := SchemaFor[secretCollections]()
+	if s.Properties["self"].Items.Properties != nil {
+		t.Error("expected recursive type to stop at the first repetition")
+	}
+}
+
+func TestSchema_JSONRoundTrip(t *testing.T) {
+	t.Parallel()
+	data, err := json.Marshal(SchemaFor[schemaTarget]())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var back Schema
+	if err = json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(back.Properties["backup"].Type, SchemaTypes{"object", "null"}) {
+		t.Errorf("unexpected backup type: %v", back.Properties["backup"].Type)
+	}
+	if back.Properties["server"].Properties["port"].Default != float64(8080) {
+		t.Errorf("unexpected default: %v", back.Properties["server"].Properties["port"].Default)
+	}
+
+	var b Schema
+	if err = json.Unmarshal([]byte(`{"additionalProperties": false}`), &b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.AdditionalProperties.Bool == nil || *b.AdditionalProperties.Bool {
+		t.Errorf("expected boolean schema, got %+v", b.AdditionalProperties)
+	}
+	out, _ := json.Marshal(b)
+	if string(out) != `{"additionalProperties":false}` {
+		t.Errorf("unexpected encoding: %s", out)
+	}
+}
+
+const testSchema = `{
+  "type": "object",
+  "required": ["database"],
+  "properties": {
+    "database": {
+      "type": "object",
+      "required": ["host", "port"],
+      "additionalProperties": false,
+      "properties": {
+        "host": {"type": "string", "pattern": "^[a-z.]+$"},
+        "port": {"type": "integer", "minimum": 1, "maximum": 65535},
+        "mode": {"enum": ["ro", "rw"]},
+        "password": {"type": "string", "pattern": "^.{8,}$"}
+      }
+    },
+    "replicas": {
+      "type": "array",
+      "items": {"type": "object", "properties": {"weight": {"type": "number"}}}
+    },
+    "labels": {"type": "object", "additionalProperties": {"type": "string"}}
+  }
+}`
+
+func TestMatchesSchema_Valid(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"database": map[string]any{"host": "db.local", "port": 5432, "mode": "ro"},
+		"replicas": []any{map[string]any{"weight": 0.5}},
+		"labels":   map[string]any{"team": "core"},
+	})
+	if err := cfg.Validate(MatchesSchema([]byte(testSchema))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMatchesSchema_StrictTypes(t *testing.T) {
+	t.Parallel()
+	schema := []byte(`{"properties": {
+  "port": {"type": "integer", "maximum": 10},
+  "ratio": {"type": "number"},
+  "debug": {"type": "boolean"},
+  "name": {"type": "string"},
+  "mode": {"enum": [1, true]},
+  "whole": {"type": "integer"}
+}}`)
+	cfg := newTestConfig(map[string]any{
+		"port": "50", "ratio": "0.5", "debug": "yes", "name": 5, "mode": "1", "whole": 2.0,
+	})
+
+	var ve *ValidationError
+	if err := cfg.Validate(MatchesSchema(schema)); !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	want := []string{
+		`"debug": expected boolean, got string`,
+		`"mode": value 1 is not one of [1 true]`,
+		`"name": expected string, got integer`,
+		`"port": expected integer, got string`,
+		`"ratio": expected number, got string`,
+	}
+	if !slices.Equal(ve.Violations, want) {
+		t.Errorf("unexpected violations:\n%s", strings.Join(ve.Violations, "\n"))
+	}
+}
+
+func TestMatchesSchema_TypeCoercion(t *testing.T) {
+	t.Parallel()
+	schema := []byte(`{"properties": {
+  "port": {"type": "integer", "maximum": 10},
+  "ratio": {"type": "number"},
+  "debug": {"type": "boolean"},
+  "name": {"type": "string"},
+  "mode": {"enum": [1, true]}
+}}`)
+	cfg := newTestConfig(map[string]any{
+		"port": "50", "ratio": "0.5", "debug": "yes", "name": 5, "mode": "1",
+	})
+	err := cfg.Validate(MatchesSchema(schema, WithTypeCoercion()))
+	if err == nil || err.Error() != "config: validation failed:\n  - \"port\": value 50 is out of range [-Inf, 10]" {
+		t.Errorf("unexpected result: %v", err)
+	}
+
+	cfg = newTestConfig(map[string]any{"port": "5.5", "debug": "maybe", "ratio": []any{}})
+	var ve *ValidationError
+	if err = cfg.Validate(MatchesSchema(schema, WithTypeCoercion())); !errors.As(err, &ve) || len(ve.Violations) != 3 {
+		t.Errorf("expected three violations, got %v", err)
+	}
+}
+
+func TestMatchesSchema_Violations(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"database": map[string]any{"host": "DB", "port": 70000, "mode": "wo", "user": "x"},
+		"replicas": []any{map[string]any{"weight": "heavy"}},
+		"labels":   map[string]any{"team": 1},
+	})
+	err := cfg.Validate(Required("database.host"), MatchesSchema([]byte(testSchema)))
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	want := []string{
+		`"database.host": value "DB" does not match pattern "^[a-z.]+$"`,
+		`"database.mode": value wo is not one of [ro rw]`,
+		`"database.port": value 70000 is out of range [1, 65535]`,
+		`"database.user": key is not allowed`,
+		`"labels.team": expected string, got integer`,
+		`"replicas[0].weight": expected number, got string`,
+	}
+	if !slices.Equal(ve.Violations, want) {
+		t.Errorf("unexpected violations:\n%s", strings.Join(ve.Violations, "\n"))
+	}
+}
+
+func TestMatchesSchema_Required(t *testing.T) {
+	t.Parallel()
+	err := newTestConfig(map[string]any{"database": map[string]any{}}).Validate(MatchesSchema([]byte(testSchema)))
+	if err == nil || !strings.Contains(err.Error(), `"database.host": required key is missing`) {
+		t.Errorf("expected missing host, got %v", err)
+	}
+}
+
+func TestMatchesSchema_InvalidSchema(t *testing.T) {
+	t.Parallel()
+	err := newTestConfig(nil).Validate(MatchesSchema([]byte("{")))
+	if err == nil || !strings.Contains(err.Error(), "invalid schema") {
+		t.Errorf("expected invalid schema error, got %v", err)
+	}
+}
+
+func TestMatchesSchema_UnsupportedKeywords(t *testing.T) {
+	t.Parallel()
+	for _, schema := range []string{
+		`{"$ref": "#/$defs/port"}`,
+		`{"oneOf": [{"type": "string"}]}`,
+		`{"properties": {"name": {"type": "string", "minLength": 3}}}`,
+		`{"properties": {"ports": {"type": "array", "minItems": 1}}}`,
+		`{"properties": {"port": {"exclusiveMinimum": 0}}}`,
+		`{"items": {"const": 1}}`,
+	} {
+		err := newTestConfig(map[string]any{"name": "x"}).Validate(MatchesSchema([]byte(schema)))
+		if err == nil || !strings.Contains(err.Error(), "config: invalid schema: unsupported keyword") {
+			t.Errorf("%s: expected unsupported keyword error, got %v", schema, err)
+		}
+	}
+
+	annotated := `{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "app", "description": "d",
+"properties": {"name": {"type": "string", "examples": ["x"], "$comment": "c"}}}`
+	if err := newTestConfig(map[string]any{"name": "x"}).Validate(MatchesSchema([]byte(annotated))); err != nil {
+		t.Errorf("expected annotations to be accepted, got %v", err)
+	}
+}
+
+func TestMatchesSchema_GeneratedSchema(t *testing.T) {
+	t.Parallel()
+	data, _ := json.Marshal(SchemaFor[taggedValidationTarget]())
+	cfg := newTestConfig(map[string]any{
+		"server": map[string]any{"host": "a", "port": 8080},
+		"cache":  map[string]any{"ttl": "5m"},
+		"log":    map[string]any{"level": "trace"},
+	})
+	err := cfg.Validate(MatchesSchema(data))
+	if err == nil || !strings.Contains(err.Error(), `"log.level"`) || strings.Contains(err.Error(), "ttl") {
+		t.Errorf("unexpected result: %v", err)
+	}
+}
+
+func TestNew_WithSchema(t *testing.T) {
+	t.Parallel()
+	loader := &staticLoader{data: map[string]any{"database": map[string]any{"host": "db", "port": 5432, "password": "short"}}}
+	_, err := New(WithLoader(loader), WithSensitiveKeys("*.password"), WithSchema([]byte(testSchema)))
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) || len(ve.Violations) != 1 {
+		t.Fatalf("expected one violation, got %v", err)
+	}
+	if strings.Contains(ve.Violations[0], "short") || !strings.Contains(ve.Violations[0], "database.password") {
+		t.Errorf("unexpected violation: %s", ve.Violations[0])
+	}
+
+	loader.data["database"].(map[string]any)["password"] = "long-enough"
+	if _, err = New(WithLoader(loader), WithSchema([]byte(testSchema))); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+)
+
+type Secret[T any] struct {
+	value T
+}
+
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+func (s Secret[T]) Reveal() T {
+	return s.value
+}
+
+func (s Secret[T]) String() string {
+	return redactedValue
+}
+
+func (s Secret[T]) GoString() string {
+	return fmt.Sprintf("config.Secret[%s]{%s}", reflect.TypeFor[T](), redactedValue)
+}
+
+func (s Secret[T]) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		_, _ = io.WriteString(f, s.GoString())
+		return
+	}
+	_, _ = io.WriteString(f, redactedValue)
+}
+
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redactedValue)
+}
+
+func (s Secret[T]) MarshalText() ([]byte, error) {
+	return []byte(redactedValue), nil
+}
+
+func (s Secret[T]) LogValue() slog.Value {
+	return slog.StringValue(redactedValue)
+}
+
+func (s *Secret[T]) decodeConfig(d *decoder, val any, tag reflect.StructTag, at fieldPath) error {
+	converted, err := d.convertToType(val, reflect.TypeFor[T](), tag, at)
+	if err != nil {
+		return err
+	}
+	s.value = converted.Interface().(T)
+	return nil
+}
+
+type configDecoder interface {
+	decodeConfig(d *decoder, val any, tag reflect.StructTag, at fieldPath) error
+}
+
+var configDecoderType = reflect.TypeFor[configDecoder]()
+
+func isSecretType(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(configDecoderType)
+}
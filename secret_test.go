@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSecret_Reveal(t *testing.T) {
+	t.Parallel()
+	s := NewSecret("hunter2")
+	if s.Reveal() != "hunter2" {
+		t.Errorf("expected hunter2, got %q", s.Reveal())
+	}
+}
+
+func TestSecret_Printing(t *testing.T) {
+	t.Parallel()
+	s := NewSecret(12345)
+	for _, verb := range []string{"%v", "%+v", "%s", "%d", "%x", "%q"} {
+		out := fmt.Sprintf(verb, s)
+		if strings.Contains(out, "12345") || strings.Contains(out, "3039") {
+			t.Errorf("%s leaked value: %s", verb, out)
+		}
+	}
+	if got := fmt.Sprintf("%#v", s); got != "config.Secret[int]{[REDACTED]}" {
+		t.Errorf("unexpected GoString: %s", got)
+	}
+	if s.String() != redactedValue {
+		t.Errorf("unexpected String: %s", s.String())
+	}
+}
+
+type secretHolder struct {
+	User     string         `cfg:"user" json:"user"`
+	Password Secret[string] `cfg:"password" json:"password"`
+}
+
+func TestSecret_InStruct(t *testing.T) {
+	t.Parallel()
+	h := secretHolder{User: "admin", Password: NewSecret("hunter2")}
+	for _, verb := range []string{"%v", "%+v", "%#v"} {
+		if out := fmt.Sprintf(verb, h); strings.Contains(out, "hunter2") {
+			t.Errorf("%s leaked value: %s", verb, out)
+		}
+	}
+
+	data, err := json.Marshal(h)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "hunter2") || !strings.Contains(string(data), redactedValue) {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
+
+func TestSecret_LogValue(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	logger.Info("cfg", "password", NewSecret("hunter2"))
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("slog leaked value: %s", buf.String())
+	}
+}
+
+func TestSecret_MarshalText(t *testing.T) {
+	t.Parallel()
+	text, _ := NewSecret("x").MarshalText()
+	if string(text) != redactedValue {
+		t.Errorf("unexpected text: %s", text)
+	}
+}
+
+type secretTarget struct {
+	Password Secret[string]        `cfg:"password"`
+	Port     Secret[int]           `cfg:"port"`
+	Timeout  Secret[time.Duration] `cfg:"timeout" default:"5s"`
+	Token    *Secret[string]       `cfg:"token"`
+}
+
+func TestUnmarshal_Secret(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{"password": "hunter2", "port": "5432", "token": "abc"})
+	var target secretTarget
+	if err := cfg.Unmarshal("", &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Password.Reveal() != "hunter2" || target.Port.Reveal() != 5432 {
+		t.Errorf("unexpected values: %+v", target)
+	}
+	if target.Timeout.Reveal() != 5*time.Second {
+		t.Errorf("expected default timeout, got %v", target.Timeout.Reveal())
+	}
+	if target.Token == nil || target.Token.Reveal() != "abc" {
+		t.Error("expected pointer secret to be set")
+	}
+}
+
+func TestUnmarshal_SecretConversionError(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{"port": "not-a-number"})
+	var target secretTarget
+	if err := cfg.Unmarshal("", &target); err == nil {
+		t.Fatal("expected conversion error")
+	}
+}
+
+func TestWithSensitiveStruct_SecretFields(t *testing.T) {
+	t.Parallel()
+	cfg, err := New(WithSensitiveStruct("db", secretTarget{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, key := range []string{"db.password", "db.port", "db.token"} {
+		if !cfg.IsSensitive(key) {
+			t.Errorf("expected %s to be sensitive", key)
+		}
+	}
+}
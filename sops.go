@@ -0,0 +1,422 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/goccy/go-yaml"
+	"golang.org/x/crypto/openpgp"                //nolint:staticcheck // SOPS still stores PGP-wrapped data keys
+	pgparmor "golang.org/x/crypto/openpgp/armor" //nolint:staticcheck // SOPS still stores PGP-wrapped data keys
+)
+
+const sopsMetadataKey = "sops"
+
+var sopsValuePattern = regexp.MustCompile(`^ENC\[AES256_GCM,data:([^,]*),iv:([^,]*),tag:([^,]*),type:([^\]]*)\]$`)
+
+type sopsKeyring struct {
+	ageKeys     []string
+	ageKeyFiles []string
+	pgpKeyFiles []string
+}
+
+func (k *sopsKeyring) ageIdentities() ([]age.Identity, error) {
+	keys := append([]string(nil), k.ageKeys...)
+	files := append([]string(nil), k.ageKeyFiles...)
+
+	if v := os.Getenv("SOPS_AGE_KEY"); v != "" {
+		keys = append(keys, v)
+	}
+	if v := os.Getenv("SOPS_AGE_KEY_FILE"); v != "" {
+		files = append(files, v)
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		if def := filepath.Join(dir, "sops", "age", "keys.txt"); fileExists(def) {
+			files = append(files, def)
+		}
+	}
+
+	for _, f := range files {
+		data, err := os.ReadFile(f) // #nosec G304 -- key file path is provided by the application
+		if err != nil {
+			return nil, fmt.Errorf("config: cannot read age key file: %w", err)
+		}
+		keys = append(keys, string(data))
+	}
+
+	var identities []age.Identity
+	for _, key := range keys {
+		parsed, err := age.ParseIdentities(strings.NewReader(key))
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid age identity: %w", err)
+		}
+		identities = append(identities, parsed...)
+	}
+
+	return identities, nil
+}
+
+func (k *sopsKeyring) pgpEntities() (openpgp.EntityList, error) {
+	files := append([]string(nil), k.pgpKeyFiles...)
+	if v := os.Getenv("SOPS_PGP_KEY_FILE"); v != "" {
+		files = append(files, v)
+	}
+
+	var entities openpgp.EntityList
+	for _, f := range files {
+		file, err := os.Open(f) // #nosec G304 -- key file path is provided by the application
+		if err != nil {
+			return nil, fmt.Errorf("config: cannot read PGP key file: %w", err)
+		}
+		parsed, err := openpgp.ReadArmoredKeyRing(file)
+		_ = file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid PGP key ring: %w", err)
+		}
+		entities = append(entities, parsed...)
+	}
+	return entities, nil
+}
+
+func isSOPSDocument(m map[string]any) bool {
+	meta, ok := m[sopsMetadataKey].(map[string]any)
+	if !ok {
+		return false
+	}
+	_, hasMAC := meta["mac"]
+	return hasMAC
+}
+
+func decryptSOPSDocument(data []byte, m map[string]any, keyring *sopsKeyring) (map[string]any, []string, error) {
+	order, err := parseSOPSKeyOrder(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return decryptSOPS(m, order, keyring)
+}
+
+type sopsDecrypter struct {
+	dataKey          []byte
+	order            sopsKeyOrder
+	macOnlyEncrypted bool
+	hash             hash.Hash
+	decrypted        []string
+}
+
+func decryptSOPS(m map[string]any, order sopsKeyOrder, keyring *sopsKeyring) (map[string]any, []string, error) {
+	meta, _ := m[sopsMetadataKey].(map[string]any)
+
+	dataKey, err := sopsDataKey(meta, keyring)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d := &sopsDecrypter{dataKey: dataKey, order: order, hash: sha512.New()}
+	d.macOnlyEncrypted, _ = meta["mac_only_encrypted"].(bool)
+
+	out := make(map[string]any, len(m))
+	for _, k := range order.keys(m, "") {
+		if k == sopsMetadataKey {
+			continue
+		}
+		decrypted, err := d.decryptValue(m[k], []string{k}, k)
+		if err != nil {
+			return nil, nil, err
+		}
+		out[k] = decrypted
+	}
+
+	if err = d.verifyMAC(meta); err != nil {
+		return nil, nil, err
+	}
+	return out, d.decrypted, nil
+}
+
+func (d *sopsDecrypter) verifyMAC(meta map[string]any) error {
+	enc, _ := meta["mac"].(string)
+	if !sopsValuePattern.MatchString(enc) {
+		return fmt.Errorf("%w: sops mac is missing or malformed", ErrDecrypt)
+	}
+	lastModified, err := sopsLastModified(meta["lastmodified"])
+	if err != nil {
+		return errors.Join(ErrDecrypt, err)
+	}
+
+	mac, err := decryptSOPSString(enc, d.dataKey, lastModified)
+	if err != nil {
+		return fmt.Errorf("sops mac: %w", err)
+	}
+	want := fmt.Sprintf("%X", d.hash.Sum(nil))
+	if got, _ := mac.(string); subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+		return fmt.Errorf("%w: sops mac does not match the document", ErrDecrypt)
+	}
+	return nil
+}
+
+func sopsLastModified(v any) (string, error) {
+	switch val := v.(type) {
+	case time.Time:
+		return val.Format(time.RFC3339), nil
+	case string:
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			return "", fmt.Errorf("invalid sops lastmodified %q", val)
+		}
+		return t.Format(time.RFC3339), nil
+	default:
+		return "", errors.New("sops metadata has no lastmodified")
+	}
+}
+
+func sopsDataKey(meta map[string]any, keyring *sopsKeyring) ([]byte, error) {
+	var errs []error
+
+	if entries := sopsKeyEntries(meta, "age"); len(entries) > 0 {
+		identities, err := keyring.ageIdentities()
+		if err != nil {
+			return nil, err
+		}
+		if len(identities) > 0 {
+			for _, enc := range entries {
+				key, err := decryptAgeDataKey(enc, identities)
+				if err == nil {
+					return key, nil
+				}
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if entries := sopsKeyEntries(meta, "pgp"); len(entries) > 0 {
+		entities, err := keyring.pgpEntities()
+		if err != nil {
+			return nil, err
+		}
+		if len(entities) > 0 {
+			for _, enc := range entries {
+				key, err := decryptPGPDataKey(enc, entities)
+				if err == nil {
+					return key, nil
+				}
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return nil, errors.Join(
+		fmt.Errorf("%w: no local age or PGP key can decrypt the sops data key", ErrNoDecryptionKey),
+		errors.Join(errs...),
+	)
+}
+
+func sopsKeyEntries(meta map[string]any, group string) []string {
+	items, _ := meta[group].([]any)
+	var out []string
+	for _, item := range items {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if enc, ok := entry["enc"].(string); ok && enc != "" {
+			out = append(out, enc)
+		}
+	}
+	return out
+}
+
+func decryptAgeDataKey(enc string, identities []age.Identity) ([]byte, error) {
+	r, err := age.Decrypt(armor.NewReader(strings.NewReader(enc)), identities...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func decryptPGPDataKey(enc string, entities openpgp.EntityList) ([]byte, error) {
+	block, err := pgparmor.Decode(strings.NewReader(enc))
+	if err != nil {
+		return nil, err
+	}
+	md, err := openpgp.ReadMessage(block.Body, entities, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(md.UnverifiedBody)
+}
+
+func (d *sopsDecrypter) decryptValue(v any, path []string, at string) (any, error) {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for _, k := range d.order.keys(val, at) {
+			childPath := append(append([]string(nil), path...), k)
+			decrypted, err := d.decryptValue(val[k], childPath, joinKey(at, k))
+			if err != nil {
+				return nil, err
+			}
+			out[k] = decrypted
+		}
+		return out, nil
+
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			decrypted, err := d.decryptValue(item, path, indexKey(at, i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = decrypted
+		}
+		return out, nil
+
+	case nil:
+		return nil, nil
+
+	default:
+		return d.decryptLeaf(v, path)
+	}
+}
+
+func (d *sopsDecrypter) decryptLeaf(v any, path []string) (any, error) {
+	s, ok := v.(string)
+	encrypted := ok && sopsValuePattern.MatchString(s)
+
+	if encrypted {
+		key := strings.Join(path, ".")
+		decrypted, err := decryptSOPSString(s, d.dataKey, strings.Join(path, ":")+":")
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+		d.decrypted = append(d.decrypted, key)
+		v = decrypted
+	}
+
+	if encrypted || !d.macOnlyEncrypted {
+		_, _ = io.WriteString(d.hash, sopsMACBytes(v))
+	}
+	return v, nil
+}
+
+func sopsMACBytes(v any) string {
+	switch val := v.(type) {
+	case bool:
+		if val {
+			return "True"
+		}
+		return "False"
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+type sopsKeyOrder map[string][]string
+
+func parseSOPSKeyOrder(data []byte) (sopsKeyOrder, error) {
+	var doc yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(data, &doc, yaml.UseOrderedMap()); err != nil {
+		return nil, yamlError(err)
+	}
+	order := make(sopsKeyOrder)
+	order.record(doc, "")
+	return order, nil
+}
+
+func (o sopsKeyOrder) record(v any, at string) {
+	switch val := v.(type) {
+	case yaml.MapSlice:
+		keys := make([]string, 0, len(val))
+		for _, item := range val {
+			k := fmt.Sprintf("%v", item.Key)
+			keys = append(keys, k)
+			o.record(item.Value, joinKey(at, k))
+		}
+		o[at] = keys
+	case []any:
+		for i, item := range val {
+			o.record(item, indexKey(at, i))
+		}
+	}
+}
+
+func (o sopsKeyOrder) keys(m map[string]any, at string) []string {
+	if keys := o[at]; len(keys) == len(m) {
+		return keys
+	}
+	return slices.Sorted(maps.Keys(m))
+}
+
+func decryptSOPSString(s string, dataKey []byte, aad string) (any, error) {
+	match := sopsValuePattern.FindStringSubmatch(s)
+	if match == nil {
+		return s, nil
+	}
+
+	var parts [3][]byte
+	for i := range parts {
+		decoded, err := base64.StdEncoding.DecodeString(match[i+1])
+		if err != nil {
+			return nil, errors.Join(ErrDecrypt, err)
+		}
+		parts[i] = decoded
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, errors.Join(ErrDecrypt, err)
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, errors.Join(ErrDecrypt, err)
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(aad))
+	if err != nil {
+		return nil, errors.Join(ErrDecrypt, err)
+	}
+
+	return parseSOPSPlaintext(string(plaintext), match[4])
+}
+
+func parseSOPSPlaintext(s, typ string) (any, error) {
+	switch typ {
+	case "int":
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, errors.Join(ErrDecrypt, err)
+		}
+		return i, nil
+	case "float":
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, errors.Join(ErrDecrypt, err)
+		}
+		return f, nil
+	case "bool":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, errors.Join(ErrDecrypt, err)
+		}
+		return b, nil
+	default:
+		return s, nil
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type sopsLoader struct {
+	paths     []string
+	basePath  string
+	optional  bool
+	decrypted []string
+	source    string
+	keyring   sopsKeyring
+}
+
+func FromSOPS(paths ...string) *sopsLoader {
+	return &sopsLoader{paths: paths}
+}
+
+func (l *sopsLoader) WithBasePath(path string) *sopsLoader {
+	l.basePath = path
+	return l
+}
+
+func (l *sopsLoader) Optional() *sopsLoader {
+	l.optional = true
+	return l
+}
+
+func (l *sopsLoader) WithAgeKey(identity string) *sopsLoader {
+	l.keyring.ageKeys = append(l.keyring.ageKeys, identity)
+	return l
+}
+
+func (l *sopsLoader) WithAgeKeyFile(path string) *sopsLoader {
+	l.keyring.ageKeyFiles = append(l.keyring.ageKeyFiles, path)
+	return l
+}
+
+func (l *sopsLoader) WithPGPKeyFile(path string) *sopsLoader {
+	l.keyring.pgpKeyFiles = append(l.keyring.pgpKeyFiles, path)
+	return l
+}
+
+func (l *sopsLoader) apply(b *builder) {
+	b.loaders = append(b.loaders, l)
+}
+
+func (l *sopsLoader) sensitiveKeys() []string {
+	return l.decrypted
+}
+
+func (l *sopsLoader) sourceName() string {
+	return l.source
+}
+
+func (l *sopsLoader) Load() (map[string]any, error) {
+	var details []LoadErrorDetail
+
+	for _, path := range l.paths {
+		absPath, err := resolveSecurePath(path, l.basePath)
+		if err != nil {
+			details = append(details, LoadErrorDetail{Path: path, Reason: err.Error()})
+			continue
+		}
+
+		if !fileExists(absPath) {
+			details = append(details, LoadErrorDetail{Path: path, Reason: "file not found"})
+			continue
+		}
+
+		data, err := os.ReadFile(absPath) // #nosec G304 -- path validated by resolveSecurePath
+		if err != nil {
+			details = append(details, LoadErrorDetail{Path: path, Reason: err.Error()})
+			continue
+		}
+
+		l.source = path
+		var cfg map[string]any
+		if strings.EqualFold(filepath.Ext(absPath), ".json") {
+			cfg, err = parseJSON(data)
+		} else {
+			cfg, err = parseYAML(data)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !isSOPSDocument(cfg) {
+			return nil, fmt.Errorf("%w: %q has no sops metadata", ErrDecrypt, path)
+		}
+
+		cfg, l.decrypted, err = decryptSOPSDocument(data, cfg, &l.keyring)
+		return cfg, err
+	}
+
+	if l.optional {
+		return make(map[string]any), nil
+	}
+
+	return nil, &LoadError{Message: "no valid SOPS configuration source found", Details: details}
+}
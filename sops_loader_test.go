@@ -0,0 +1,281 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func sopsAgeFixture(t *testing.T) (*age.X25519Identity, []byte, string) {
+	t.Helper()
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataKey := newSOPSDataKey(t)
+	return identity, dataKey, ageWrapKey(t, dataKey, identity.Recipient())
+}
+
+func sopsYAMLDocument(t *testing.T, dataKey []byte, wrapped string) string {
+	t.Helper()
+	return sopsYAMLDocumentWith(t, dataKey, wrapped, "hunter2")
+}
+
+func sopsYAMLDocumentWith(t *testing.T, dataKey []byte, wrapped, password string) string {
+	t.Helper()
+	indented := "            " + strings.ReplaceAll(strings.TrimSpace(wrapped), "\n", "\n            ")
+	return fmt.Sprintf(`database:
+    password: %s
+    host: localhost
+sops:
+    age:
+        - recipient: age1test
+          enc: |
+%s
+    lastmodified: "%s"
+    mac: %s
+    version: 3.8.1
+`, sopsEncryptString(t, dataKey, password, "str", "database:password:"), indented,
+		sopsTestLastModified, sopsMAC(t, dataKey, password, "localhost"))
+}
+
+func TestSOPSLoader_YAML(t *testing.T) {
+	t.Parallel()
+	identity, dataKey, wrapped := sopsAgeFixture(t)
+	dir := t.TempDir()
+	p := writeTestFile(t, dir, "secrets.enc.yaml", sopsYAMLDocument(t, dataKey, wrapped))
+
+	loader := FromSOPS(p).WithBasePath(dir).WithAgeKey(identity.String())
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys := loader.sensitiveKeys(); len(keys) != 1 || keys[0] != "database.password" {
+		t.Errorf("expected decrypted key to be reported, got %v", keys)
+	}
+	db := cfg["database"].(map[string]any)
+	if db["password"] != "hunter2" || db["host"] != "localhost" {
+		t.Errorf("unexpected values: %v", db)
+	}
+	if _, ok := cfg["sops"]; ok {
+		t.Error("expected sops metadata to be removed")
+	}
+}
+
+func TestSOPSLoader_JSON(t *testing.T) {
+	t.Parallel()
+	identity, dataKey, wrapped := sopsAgeFixture(t)
+	doc := map[string]any{
+		"token": sopsEncryptString(t, dataKey, "abc", "str", "token:"),
+		"sops": map[string]any{
+			"mac":          sopsMAC(t, dataKey, "abc"),
+			"lastmodified": sopsTestLastModified,
+			"age":          []any{map[string]any{"enc": wrapped}},
+		},
+	}
+	data, _ := json.Marshal(doc)
+	dir := t.TempDir()
+	p := writeTestFile(t, dir, "secrets.json", string(data))
+	keyFile := writeTestFile(t, dir, "keys.txt", identity.String()+"\n")
+
+	cfg, err := FromSOPS(p).WithBasePath(dir).WithAgeKeyFile(keyFile).Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg["token"] != "abc" {
+		t.Errorf("expected abc, got %v", cfg["token"])
+	}
+}
+
+func TestSOPSLoader_EnvIdentity(t *testing.T) {
+	identity, dataKey, wrapped := sopsAgeFixture(t)
+	t.Setenv("SOPS_AGE_KEY", identity.String())
+	dir := t.TempDir()
+	p := writeTestFile(t, dir, "secrets.yaml", sopsYAMLDocument(t, dataKey, wrapped))
+
+	cfg, err := New(FromSOPS(p).WithBasePath(dir))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GetString("database.password") != "hunter2" {
+		t.Errorf("expected hunter2, got %q", cfg.GetString("database.password"))
+	}
+}
+
+func TestSOPSLoader_SecretsAreNotTemplates(t *testing.T) {
+	t.Parallel()
+	for _, secret := range []string{"p{{ .x }}q", "{{ unbalanced", `{{ env "HOME" }}`} {
+		identity, dataKey, wrapped := sopsAgeFixture(t)
+		dir := t.TempDir()
+		p := writeTestFile(t, dir, "secrets.yaml", sopsYAMLDocumentWith(t, dataKey, wrapped, secret))
+
+		cfg, err := New(FromSOPS(p).WithBasePath(dir).WithAgeKey(identity.String()))
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", secret, err)
+		}
+		if got := cfg.GetString("database.password"); got != secret {
+			t.Errorf("expected secret %q unchanged, got %q", secret, got)
+		}
+	}
+}
+
+func TestSOPSLoader_SecretsInListsAreNotTemplates(t *testing.T) {
+	t.Parallel()
+	identity, dataKey, wrapped := sopsAgeFixture(t)
+	indented := "            " + strings.ReplaceAll(strings.TrimSpace(wrapped), "\n", "\n            ")
+	secret := "p{{ end }}"
+	doc := fmt.Sprintf(`users:
+    - password: %s
+      name: '{{ "alice" | upper }}'
+sops:
+    age:
+        - recipient: age1test
+          enc: |
+%s
+    lastmodified: "%s"
+    mac: %s
+    version: 3.8.1
+`, sopsEncryptString(t, dataKey, secret, "str", "users:password:"), indented,
+		sopsTestLastModified, sopsMAC(t, dataKey, secret, `{{ "alice" | upper }}`))
+
+	dir := t.TempDir()
+	p := writeTestFile(t, dir, "secrets.yaml", doc)
+	cfg, err := New(FromSOPS(p).WithBasePath(dir).WithAgeKey(identity.String()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	user := cfg.Get("users").([]any)[0].(map[string]any)
+	if user["password"] != secret || user["name"] != "ALICE" {
+		t.Errorf("unexpected user: %v", user)
+	}
+}
+
+func TestSOPSLoader_OverriddenSecretIsRendered(t *testing.T) {
+	t.Parallel()
+	identity, dataKey, wrapped := sopsAgeFixture(t)
+	dir := t.TempDir()
+	p := writeTestFile(t, dir, "secrets.yaml", sopsYAMLDocumentWith(t, dataKey, wrapped, "{{ raw"))
+	override := writeTestFile(t, dir, "override.yaml", "database:\n  password: '{{ \"x\" | upper }}'\n")
+
+	cfg, err := New(
+		FromSOPS(p).WithBasePath(dir).WithAgeKey(identity.String()),
+		FromYAML(override).WithBasePath(dir),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.GetString("database.password"); got != "X" {
+		t.Errorf("expected rendered override, got %q", got)
+	}
+}
+
+func TestYAMLLoader_DetectsSOPS(t *testing.T) {
+	identity, dataKey, wrapped := sopsAgeFixture(t)
+	t.Setenv("SOPS_AGE_KEY", identity.String())
+	dir := t.TempDir()
+	p := writeTestFile(t, dir, "secrets.yaml", sopsYAMLDocument(t, dataKey, wrapped))
+
+	cfg, err := FromYAML(p).WithBasePath(dir).Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg["database"].(map[string]any)["password"] != "hunter2" {
+		t.Errorf("expected decrypted password, got %v", cfg["database"])
+	}
+}
+
+func TestSOPSLoader_TamperedDocument(t *testing.T) {
+	t.Parallel()
+	identity, dataKey, wrapped := sopsAgeFixture(t)
+	dir := t.TempDir()
+	doc := strings.Replace(sopsYAMLDocument(t, dataKey, wrapped), "host: localhost", "host: evil.example", 1)
+	p := writeTestFile(t, dir, "secrets.yaml", doc)
+
+	_, err := FromSOPS(p).WithBasePath(dir).WithAgeKey(identity.String()).Load()
+	if !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+}
+
+func TestJSONLoader_DetectsSOPSWithPGPKeyFile(t *testing.T) {
+	entity, keyFile := pgpFixture(t)
+	t.Setenv("SOPS_PGP_KEY_FILE", keyFile)
+	dataKey := newSOPSDataKey(t)
+	doc := map[string]any{
+		"token": sopsEncryptString(t, dataKey, "abc", "str", "token:"),
+		"sops": map[string]any{
+			"mac":          sopsMAC(t, dataKey, "abc"),
+			"lastmodified": sopsTestLastModified,
+			"pgp":          []any{map[string]any{"fp": "x", "enc": pgpWrapKey(t, dataKey, entity)}},
+		},
+	}
+	data, _ := json.Marshal(doc)
+	dir := t.TempDir()
+	p := writeTestFile(t, dir, "secrets.json", string(data))
+
+	cfg, err := FromJSON(p).WithBasePath(dir).Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg["token"] != "abc" {
+		t.Errorf("expected abc, got %v", cfg["token"])
+	}
+}
+
+func TestSOPSLoader_NotSOPS(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	p := writeTestFile(t, dir, "plain.yaml", "a: b\n")
+	_, err := FromSOPS(p).WithBasePath(dir).Load()
+	if !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+}
+
+func TestSOPSLoader_FileNotFound(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	_, err := FromSOPS(filepath.Join(dir, "nope.yaml")).WithBasePath(dir).Load()
+	var le *LoadError
+	if !errors.As(err, &le) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+
+	cfg, err := FromSOPS(filepath.Join(dir, "nope.yaml")).WithBasePath(dir).Optional().Load()
+	if err != nil || len(cfg) != 0 {
+		t.Errorf("expected empty optional result, got %v, %v", cfg, err)
+	}
+}
+
+func TestSOPSLoader_PathTraversal(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	_, err := FromSOPS("/etc/passwd").WithBasePath(dir).Load()
+	if err == nil {
+		t.Fatal("expected error for path outside base")
+	}
+}
+
+func TestSOPSLoader_InvalidYAML(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	p := writeTestFile(t, dir, "bad.yaml", ":\n  :\n    : [invalid yaml")
+	_, err := FromSOPS(p).WithBasePath(dir).Load()
+	if !errors.Is(err, ErrParseYAML) {
+		t.Fatalf("expected ErrParseYAML, got %v", err)
+	}
+}
+
+func TestSOPSLoader_Apply(t *testing.T) {
+	t.Parallel()
+	b := &builder{}
+	FromSOPS("a.yaml").apply(b)
+	if len(b.loaders) != 1 {
+		t.Errorf("expected 1 loader, got %d", len(b.loaders))
+	}
+}
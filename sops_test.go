@@ -0,0 +1,317 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"golang.org/x/crypto/openpgp"                //nolint:staticcheck // test fixture for SOPS PGP keys
+	pgparmor "golang.org/x/crypto/openpgp/armor" //nolint:staticcheck // test fixture for SOPS PGP keys
+)
+
+func sopsEncryptString(t *testing.T, dataKey []byte, plaintext, typ, aad string) string {
+	t.Helper()
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := make([]byte, 32)
+	_, _ = rand.Read(iv)
+	sealed := gcm.Seal(nil, iv, []byte(plaintext), []byte(aad))
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	enc := base64.StdEncoding.EncodeToString
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]", enc(data), enc(iv), enc(tag), typ)
+}
+
+const sopsTestLastModified = "2024-05-01T10:00:00Z"
+
+func sopsMAC(t *testing.T, dataKey []byte, values ...string) string {
+	t.Helper()
+	h := sha512.New()
+	for _, v := range values {
+		h.Write([]byte(v))
+	}
+	return sopsEncryptString(t, dataKey, fmt.Sprintf("%X", h.Sum(nil)), "str", sopsTestLastModified)
+}
+
+func ageWrapKey(t *testing.T, dataKey []byte, recipient age.Recipient) string {
+	t.Helper()
+	var buf bytes.Buffer
+	aw := armor.NewWriter(&buf)
+	w, err := age.Encrypt(aw, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write(dataKey)
+	_ = w.Close()
+	_ = aw.Close()
+	return buf.String()
+}
+
+func pgpWrapKey(t *testing.T, dataKey []byte, entity *openpgp.Entity) string {
+	t.Helper()
+	var buf bytes.Buffer
+	aw, err := pgparmor.Encode(&buf, "PGP MESSAGE", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := openpgp.Encrypt(aw, []*openpgp.Entity{entity}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write(dataKey)
+	_ = w.Close()
+	_ = aw.Close()
+	return buf.String()
+}
+
+func pgpFixture(t *testing.T) (*openpgp.Entity, string) {
+	t.Helper()
+	entity, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range entity.Identities {
+		id.SelfSignature.PreferredHash = []uint8{8} // SHA-256
+	}
+
+	var keyring bytes.Buffer
+	aw, _ := pgparmor.Encode(&keyring, openpgp.PrivateKeyType, nil)
+	if err = entity.SerializePrivate(aw, nil); err != nil {
+		t.Fatal(err)
+	}
+	_ = aw.Close()
+	return entity, writeTestFile(t, t.TempDir(), "key.asc", keyring.String())
+}
+
+func newSOPSDataKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return key
+}
+
+func TestIsSOPSDocument(t *testing.T) {
+	t.Parallel()
+	if !isSOPSDocument(map[string]any{"sops": map[string]any{"mac": "x"}}) {
+		t.Error("expected sops document")
+	}
+	if isSOPSDocument(map[string]any{"sops": "not a map"}) {
+		t.Error("expected non-sops document")
+	}
+	if isSOPSDocument(map[string]any{"sops": map[string]any{"version": "3"}}) {
+		t.Error("expected metadata without mac to be ignored")
+	}
+}
+
+func TestDecryptSOPS_Age(t *testing.T) {
+	t.Parallel()
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataKey := newSOPSDataKey(t)
+
+	doc := map[string]any{
+		"db": map[string]any{
+			"password": sopsEncryptString(t, dataKey, "hunter2", "str", "db:password:"),
+			"port":     sopsEncryptString(t, dataKey, "5432", "int", "db:port:"),
+			"ratio":    sopsEncryptString(t, dataKey, "0.5", "float", "db:ratio:"),
+			"tls":      sopsEncryptString(t, dataKey, "True", "bool", "db:tls:"),
+		},
+		"hosts":            []any{sopsEncryptString(t, dataKey, "a", "str", "hosts:")},
+		"name_unencrypted": "plain",
+		"sops": map[string]any{
+			"mac":          sopsMAC(t, dataKey, "hunter2", "5432", "0.5", "True", "a", "plain"),
+			"lastmodified": sopsTestLastModified,
+			"age":          []any{map[string]any{"recipient": identity.Recipient().String(), "enc": ageWrapKey(t, dataKey, identity.Recipient())}},
+		},
+	}
+
+	out, _, err := decryptSOPS(doc, nil, &sopsKeyring{ageKeys: []string{identity.String()}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := out["sops"]; ok {
+		t.Error("expected sops metadata to be removed")
+	}
+	db := out["db"].(map[string]any)
+	if db["password"] != "hunter2" || db["port"] != 5432 || db["ratio"] != 0.5 || db["tls"] != true {
+		t.Errorf("unexpected db values: %v", db)
+	}
+	if hosts := out["hosts"].([]any); hosts[0] != "a" {
+		t.Errorf("unexpected hosts: %v", hosts)
+	}
+	if out["name_unencrypted"] != "plain" {
+		t.Errorf("expected plain value untouched, got %v", out["name_unencrypted"])
+	}
+}
+
+func TestDecryptSOPS_PGP(t *testing.T) {
+	t.Parallel()
+	entity, keyFile := pgpFixture(t)
+	dataKey := newSOPSDataKey(t)
+
+	doc := map[string]any{
+		"token": sopsEncryptString(t, dataKey, "abc", "str", "token:"),
+		"sops": map[string]any{
+			"mac":          sopsMAC(t, dataKey, "abc"),
+			"lastmodified": sopsTestLastModified,
+			"pgp":          []any{map[string]any{"fp": "x", "enc": pgpWrapKey(t, dataKey, entity)}},
+		},
+	}
+
+	out, _, err := decryptSOPS(doc, nil, &sopsKeyring{pgpKeyFiles: []string{keyFile}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out["token"] != "abc" {
+		t.Errorf("expected abc, got %v", out["token"])
+	}
+}
+
+func TestDecryptSOPS_NoMatchingKey(t *testing.T) {
+	t.Parallel()
+	owner, _ := age.GenerateX25519Identity()
+	other, _ := age.GenerateX25519Identity()
+	dataKey := newSOPSDataKey(t)
+
+	doc := map[string]any{
+		"sops": map[string]any{
+			"mac": "ENC[...]",
+			"age": []any{map[string]any{"enc": ageWrapKey(t, dataKey, owner.Recipient())}},
+		},
+	}
+
+	_, _, err := decryptSOPS(doc, nil, &sopsKeyring{ageKeys: []string{other.String()}})
+	if !errors.Is(err, ErrNoDecryptionKey) {
+		t.Fatalf("expected ErrNoDecryptionKey, got %v", err)
+	}
+}
+
+func TestDecryptSOPS_TamperedPath(t *testing.T) {
+	t.Parallel()
+	identity, _ := age.GenerateX25519Identity()
+	dataKey := newSOPSDataKey(t)
+
+	doc := map[string]any{
+		"moved": sopsEncryptString(t, dataKey, "v", "str", "original:"),
+		"sops": map[string]any{
+			"mac": "ENC[...]",
+			"age": []any{map[string]any{"enc": ageWrapKey(t, dataKey, identity.Recipient())}},
+		},
+	}
+
+	_, _, err := decryptSOPS(doc, nil, &sopsKeyring{ageKeys: []string{identity.String()}})
+	if !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+}
+
+func TestDecryptSOPS_MAC(t *testing.T) {
+	t.Parallel()
+	identity, _ := age.GenerateX25519Identity()
+	dataKey := newSOPSDataKey(t)
+	keyring := &sopsKeyring{ageKeys: []string{identity.String()}}
+
+	document := func(mac string, hosts []string, name string) map[string]any {
+		items := make([]any, len(hosts))
+		for i, h := range hosts {
+			items[i] = sopsEncryptString(t, dataKey, h, "str", "hosts:")
+		}
+		return map[string]any{
+			"hosts": items,
+			"name":  name,
+			"sops": map[string]any{
+				"mac":          mac,
+				"lastmodified": sopsTestLastModified,
+				"age":          []any{map[string]any{"enc": ageWrapKey(t, dataKey, identity.Recipient())}},
+			},
+		}
+	}
+	valid := sopsMAC(t, dataKey, "a", "b", "svc")
+
+	if _, _, err := decryptSOPS(document(valid, []string{"a", "b"}, "svc"), nil, keyring); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]map[string]any{
+		"garbage mac":       document("ENC[garbage]", []string{"a", "b"}, "svc"),
+		"missing mac":       document("", []string{"a", "b"}, "svc"),
+		"reordered values":  document(valid, []string{"b", "a"}, "svc"),
+		"dropped value":     document(valid, []string{"a"}, "svc"),
+		"edited plain text": document(valid, []string{"a", "b"}, "other"),
+		"mac for other doc": document(sopsMAC(t, dataKey, "x"), []string{"a", "b"}, "svc"),
+	}
+	for name, doc := range cases {
+		if _, _, err := decryptSOPS(doc, nil, keyring); !errors.Is(err, ErrDecrypt) {
+			t.Errorf("%s: expected ErrDecrypt, got %v", name, err)
+		}
+	}
+
+	doc := document(valid, []string{"a", "b"}, "svc")
+	doc["sops"].(map[string]any)["lastmodified"] = "2024-05-02T10:00:00Z"
+	if _, _, err := decryptSOPS(doc, nil, keyring); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected changed lastmodified to fail, got %v", err)
+	}
+
+	doc = document(sopsMAC(t, dataKey, "a", "b"), []string{"a", "b"}, "edited")
+	doc["sops"].(map[string]any)["mac_only_encrypted"] = true
+	if _, _, err := decryptSOPS(doc, nil, keyring); err != nil {
+		t.Errorf("expected mac_only_encrypted to ignore plain values, got %v", err)
+	}
+}
+
+func TestParseSOPSKeyOrder(t *testing.T) {
+	t.Parallel()
+	order, err := parseSOPSKeyOrder([]byte("z: 1\nitems:\n  - b: 1\n    a: 2\na:\n  y: 1\n  x: 2\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := sopsKeyOrder{"": {"z", "items", "a"}, "items[0]": {"b", "a"}, "a": {"y", "x"}}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("unexpected order: %v", order)
+	}
+	if keys := order.keys(map[string]any{"q": 1, "p": 2}, "missing"); !reflect.DeepEqual(keys, []string{"p", "q"}) {
+		t.Errorf("expected sorted fallback, got %v", keys)
+	}
+}
+
+func TestDecryptSOPS_InvalidAgeKey(t *testing.T) {
+	t.Parallel()
+	doc := map[string]any{
+		"sops": map[string]any{
+			"mac": "ENC[...]",
+			"age": []any{map[string]any{"enc": "x"}},
+		},
+	}
+	_, _, err := decryptSOPS(doc, nil, &sopsKeyring{ageKeys: []string{"not-a-key"}})
+	if err == nil {
+		t.Fatal("expected error for invalid identity")
+	}
+}
+
+func TestParseSOPSPlaintext_InvalidType(t *testing.T) {
+	t.Parallel()
+	for _, typ := range []string{"int", "float", "bool"} {
+		if _, err := parseSOPSPlaintext("nope", typ); !errors.Is(err, ErrDecrypt) {
+			t.Errorf("%s: expected ErrDecrypt, got %v", typ, err)
+		}
+	}
+	if v, _ := parseSOPSPlaintext("raw", "bytes"); v != "raw" {
+		t.Errorf("expected raw, got %v", v)
+	}
+}
@@ -8,7 +8,7 @@ import (
 	"text/template"
 )
 
-func processValue(v any, path string) (any, error) {
+func processValue(v any, path string, literal map[string]bool) (any, error) {
 	switch val := v.(type) {
 	case string:
 		if strings.Contains(val, "{{") && strings.Contains(val, "}}") {
@@ -27,7 +27,11 @@ func processValue(v any, path string) (any, error) {
 			if path != "" {
 				childPath = path + "." + k
 			}
-			processed, err := processValue(item, childPath)
+			if literal[indexSegment.ReplaceAllString(childPath, "")] {
+				out[k] = item
+				continue
+			}
+			processed, err := processValue(item, childPath, literal)
 			if err != nil {
 				return nil, err
 			}
@@ -39,7 +43,7 @@ func processValue(v any, path string) (any, error) {
 		out := make([]any, len(val))
 		for i, item := range val {
 			childPath := fmt.Sprintf("%s[%d]", path, i)
-			processed, err := processValue(item, childPath)
+			processed, err := processValue(item, childPath, literal)
 			if err != nil {
 				return nil, err
 			}
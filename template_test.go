@@ -8,7 +8,7 @@ import (
 
 func TestProcessValue_String_NoTemplate(t *testing.T) {
 	t.Parallel()
-	out, err := processValue("hello", "k")
+	out, err := processValue("hello", "k", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -20,7 +20,7 @@ func TestProcessValue_String_NoTemplate(t *testing.T) {
 func TestProcessValue_String_WithTemplate(t *testing.T) {
 	os.Setenv("TMPL_TEST_VAR", "world")
 	t.Cleanup(func() { os.Unsetenv("TMPL_TEST_VAR") })
-	out, err := processValue(`{{ env "TMPL_TEST_VAR" }}`, "k")
+	out, err := processValue(`{{ env "TMPL_TEST_VAR" }}`, "k", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -31,7 +31,7 @@ func TestProcessValue_String_WithTemplate(t *testing.T) {
 
 func TestProcessValue_String_BadTemplate(t *testing.T) {
 	t.Parallel()
-	_, err := processValue(`{{ end }}`, "k")
+	_, err := processValue(`{{ end }}`, "k", nil)
 	if err == nil {
 		t.Fatal("expected error from bad template")
 	}
@@ -40,7 +40,7 @@ func TestProcessValue_String_BadTemplate(t *testing.T) {
 func TestProcessValue_Map(t *testing.T) {
 	t.Parallel()
 	in := map[string]any{"a": "plain", "b": map[string]any{"c": "inner"}}
-	out, err := processValue(in, "")
+	out, err := processValue(in, "", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -53,16 +53,40 @@ func TestProcessValue_Map(t *testing.T) {
 func TestProcessValue_Map_Error(t *testing.T) {
 	t.Parallel()
 	in := map[string]any{"a": `{{ end }}`}
-	_, err := processValue(in, "root")
+	_, err := processValue(in, "root", nil)
 	if err == nil {
 		t.Fatal("expected error from map child")
 	}
 }
 
+func TestProcessValue_Literal(t *testing.T) {
+	t.Parallel()
+	in := map[string]any{
+		"db":    map[string]any{"password": "p{{ .x }}q", "host": `{{ "h" | upper }}`},
+		"hosts": []any{"{{ unbalanced"},
+		"users": []any{map[string]any{"password": "p{{ end }}", "name": `{{ "n" | upper }}`}},
+	}
+	out, err := processValue(in, "", map[string]bool{"db.password": true, "hosts": true, "users.password": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := out.(map[string]any)
+	db := m["db"].(map[string]any)
+	if db["password"] != "p{{ .x }}q" || db["host"] != "H" {
+		t.Errorf("unexpected db values: %v", db)
+	}
+	if hosts := m["hosts"].([]any); hosts[0] != "{{ unbalanced" {
+		t.Errorf("expected literal list, got %v", hosts)
+	}
+	if user := m["users"].([]any)[0].(map[string]any); user["password"] != "p{{ end }}" || user["name"] != "N" {
+		t.Errorf("unexpected list item: %v", user)
+	}
+}
+
 func TestProcessValue_Slice(t *testing.T) {
 	t.Parallel()
 	in := []any{"hello", 42}
-	out, err := processValue(in, "arr")
+	out, err := processValue(in, "arr", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -75,7 +99,7 @@ func TestProcessValue_Slice(t *testing.T) {
 func TestProcessValue_Slice_Error(t *testing.T) {
 	t.Parallel()
 	in := []any{`{{ end }}`}
-	_, err := processValue(in, "arr")
+	_, err := processValue(in, "arr", nil)
 	if err == nil {
 		t.Fatal("expected error from slice child")
 	}
@@ -83,7 +107,7 @@ func TestProcessValue_Slice_Error(t *testing.T) {
 
 func TestProcessValue_OtherType(t *testing.T) {
 	t.Parallel()
-	out, err := processValue(42, "k")
+	out, err := processValue(42, "k", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -0,0 +1,686 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var tomlBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+type tomlEncoder struct {
+	b       strings.Builder
+	comment func(key string) string
+}
+
+func encodeTOML(w io.Writer, m map[string]any, comment func(key string) string) error {
+	e := &tomlEncoder{comment: comment}
+	if err := e.table(m, "", ""); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, strings.TrimPrefix(e.b.String(), "\n"))
+	return err
+}
+
+func (e *tomlEncoder) table(m map[string]any, path, header string) error {
+	keys := slices.Sorted(maps.Keys(m))
+
+	for _, k := range keys {
+		v := m[k]
+		if v == nil || isTOMLTable(v) || isTOMLTableArray(v) {
+			continue
+		}
+		val, err := tomlValue(v)
+		if err != nil {
+			return fmt.Errorf("config: encode toml %s: %w", joinKey(path, k), err)
+		}
+		if src := e.comment(joinKey(path, k)); src != "" {
+			fmt.Fprintf(&e.b, "# %s\n", src)
+		}
+		fmt.Fprintf(&e.b, "%s = %s\n", tomlKey(k), val)
+	}
+
+	for _, k := range keys {
+		sub := joinKey(header, tomlKey(k))
+
+		switch v := m[k].(type) {
+		case map[string]any:
+			fmt.Fprintf(&e.b, "\n[%s]\n", sub)
+			if err := e.table(v, joinKey(path, k), sub); err != nil {
+				return err
+			}
+		case []any:
+			if !isTOMLTableArray(v) {
+				continue
+			}
+			for i, item := range v {
+				fmt.Fprintf(&e.b, "\n[[%s]]\n", sub)
+				if err := e.table(item.(map[string]any), indexKey(joinKey(path, k), i), sub); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func isTOMLTable(v any) bool {
+	_, ok := v.(map[string]any)
+	return ok
+}
+
+func isTOMLTableArray(v any) bool {
+	items, ok := v.([]any)
+	if !ok || len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		if !isTOMLTable(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func tomlKey(k string) string {
+	if tomlBareKey.MatchString(k) {
+		return k
+	}
+	return tomlString(k)
+}
+
+func tomlValue(v any) (string, error) {
+	switch val := v.(type) {
+	case string:
+		return tomlString(val), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case int:
+		return strconv.Itoa(val), nil
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case uint64:
+		if val > math.MaxInt64 {
+			return "", fmt.Errorf("integer %d is out of range for TOML", val)
+		}
+		return strconv.FormatUint(val, 10), nil
+	case float64:
+		return tomlFloat(val), nil
+	case []any:
+		parts := make([]string, 0, len(val))
+		for _, item := range val {
+			if item == nil {
+				continue
+			}
+			s, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	case map[string]any:
+		parts := make([]string, 0, len(val))
+		for _, k := range slices.Sorted(maps.Keys(val)) {
+			if val[k] == nil {
+				continue
+			}
+			s, err := tomlValue(val[k])
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, tomlKey(k)+" = "+s)
+		}
+		return "{" + strings.Join(parts, ", ") + "}", nil
+	default:
+		return tomlString(fmt.Sprintf("%v", v)), nil
+	}
+}
+
+func tomlFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eEn") {
+		s += ".0"
+	}
+	return s
+}
+
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+var (
+	tomlDate        = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	tomlDecimal     = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)$`)
+	tomlPrefixed    = regexp.MustCompile(`^0(x[0-9A-Fa-f](_?[0-9A-Fa-f])*|o[0-7](_?[0-7])*|b[01](_?[01])*)$`)
+	tomlFloatNumber = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][+-]?[0-9](_?[0-9])*)?$`)
+	tomlLeadingZero = regexp.MustCompile(`^[+-]?0[0-9_]`)
+)
+
+type tomlKind int
+
+const (
+	tomlImplicit tomlKind = iota
+	tomlDefined
+	tomlDotted
+	tomlInline
+	tomlArrayOfTables
+)
+
+type tomlParser struct {
+	s       string
+	pos     int
+	line    int
+	root    map[string]any
+	current map[string]any
+	path    string
+	kinds   map[string]tomlKind
+	inline  int
+}
+
+func parseTOML(data []byte) (map[string]any, error) {
+	root := make(map[string]any)
+	p := &tomlParser{s: string(data), line: 1, root: root, current: root, kinds: make(map[string]tomlKind)}
+	if err := p.parse(); err != nil {
+		return nil, errors.Join(ErrParseTOML, fmt.Errorf("line %d: %w", p.line, err))
+	}
+	return normalizeMap(root), nil
+}
+
+func (p *tomlParser) parse() error {
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return nil
+		}
+
+		var err error
+		if p.peek() == '[' {
+			err = p.parseHeader()
+		} else {
+			err = p.parseKeyValue(p.current, p.path)
+		}
+		if err != nil {
+			return err
+		}
+
+		p.skipBlank(false)
+		if !p.eof() && p.peek() != '\n' && p.peek() != '\r' {
+			return fmt.Errorf("unexpected %q after value", p.peek())
+		}
+	}
+}
+
+func (p *tomlParser) parseHeader() error {
+	array := strings.HasPrefix(p.s[p.pos:], "[[")
+	if array {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.s[p.pos:], closing) {
+		return fmt.Errorf("expected %q to close table header", closing)
+	}
+	p.pos += len(closing)
+
+	parent, path, err := p.table(p.root, "", keys[:len(keys)-1], tomlImplicit)
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if array {
+		p.current, p.path, err = p.appendTable(parent, path, keys)
+		return err
+	}
+
+	if p.kinds[tomlPath(path, last)] != tomlImplicit {
+		return fmt.Errorf("table %q is already defined", strings.Join(keys, "."))
+	}
+
+	p.current, p.path, err = p.table(parent, path, []string{last}, tomlImplicit)
+	if err != nil {
+		return err
+	}
+	p.kinds[p.path] = tomlDefined
+	return nil
+}
+
+func (p *tomlParser) appendTable(parent map[string]any, path string, keys []string) (map[string]any, string, error) {
+	last := keys[len(keys)-1]
+	child := tomlPath(path, last)
+
+	items, ok := parent[last].([]any)
+	if parent[last] != nil && (!ok || p.kinds[child] != tomlArrayOfTables) {
+		return nil, "", fmt.Errorf("key %q is not an array of tables", strings.Join(keys, "."))
+	}
+	p.kinds[child] = tomlArrayOfTables
+
+	table := make(map[string]any)
+	parent[last] = append(items, table)
+	path = tomlIndexPath(child, len(items))
+	p.kinds[path] = tomlDefined
+	return table, path, nil
+}
+
+func (p *tomlParser) parseKeyValue(table map[string]any, path string) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if p.eof() || p.peek() != '=' {
+		return fmt.Errorf("expected '=' after key %q", strings.Join(keys, "."))
+	}
+	p.pos++
+	p.skipBlank(false)
+
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	parent, path, err := p.table(table, path, keys[:len(keys)-1], tomlDotted)
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, exists := parent[last]; exists {
+		return fmt.Errorf("duplicate key %q", strings.Join(keys, "."))
+	}
+	parent[last] = value
+	p.kinds[tomlPath(path, last)] = tomlInline
+	return nil
+}
+
+func (p *tomlParser) table(m map[string]any, path string, keys []string, kind tomlKind) (map[string]any, string, error) {
+	for _, k := range keys {
+		path = tomlPath(path, k)
+		switch next := m[k].(type) {
+		case nil:
+			table := make(map[string]any)
+			m[k] = table
+			m = table
+			p.kinds[path] = kind
+		case map[string]any:
+			existing := p.kinds[path]
+			if existing == tomlInline || kind == tomlDotted && existing != tomlDotted {
+				return nil, "", fmt.Errorf("cannot extend table %q with dotted keys", k)
+			}
+			m = next
+		case []any:
+			if p.kinds[path] != tomlArrayOfTables || kind == tomlDotted {
+				return nil, "", fmt.Errorf("key %q is not a table", k)
+			}
+			m = next[len(next)-1].(map[string]any)
+			path = tomlIndexPath(path, len(next)-1)
+		default:
+			return nil, "", fmt.Errorf("key %q is not a table", k)
+		}
+	}
+	return m, path, nil
+}
+
+func tomlPath(path, key string) string {
+	return path + "." + strconv.Quote(key)
+}
+
+func tomlIndexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		p.skipBlank(false)
+		if p.eof() {
+			return nil, errors.New("unexpected end of input in key")
+		}
+
+		switch p.peek() {
+		case '"', '\'':
+			k, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, k)
+		default:
+			start := p.pos
+			for !p.eof() && isTOMLBareChar(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, fmt.Errorf("invalid key character %q", p.peek())
+			}
+			keys = append(keys, p.s[start:p.pos])
+		}
+
+		p.skipBlank(false)
+		if p.eof() || p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseValue() (any, error) {
+	if p.eof() {
+		return nil, errors.New("missing value")
+	}
+
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.parseString()
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	case strings.HasPrefix(p.s[p.pos:], "true"):
+		p.pos += 4
+		return true, nil
+	case strings.HasPrefix(p.s[p.pos:], "false"):
+		p.pos += 5
+		return false, nil
+	default:
+		return p.parseScalar()
+	}
+}
+
+func (p *tomlParser) parseArray() ([]any, error) {
+	p.pos++
+	items := make([]any, 0)
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, errors.New("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return items, nil
+		}
+
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+
+		p.skipBlank(true)
+		if !p.eof() && p.peek() == ',' {
+			p.pos++
+			continue
+		}
+		if p.eof() || p.peek() != ']' {
+			return nil, errors.New("expected ',' or ']' in array")
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (map[string]any, error) {
+	p.pos++
+	table := make(map[string]any)
+	p.inline++
+	path := "{" + strconv.Itoa(p.inline) + "}"
+	for {
+		p.skipBlank(false)
+		if p.eof() {
+			return nil, errors.New("unterminated inline table")
+		}
+		if p.peek() == '}' {
+			p.pos++
+			return table, nil
+		}
+
+		if err := p.parseKeyValue(table, path); err != nil {
+			return nil, err
+		}
+
+		p.skipBlank(false)
+		if !p.eof() && p.peek() == ',' {
+			p.pos++
+			continue
+		}
+		if p.eof() || p.peek() != '}' {
+			return nil, errors.New("expected ',' or '}' in inline table")
+		}
+	}
+}
+
+func (p *tomlParser) parseScalar() (any, error) {
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.peek())) {
+		p.pos++
+	}
+	token := p.s[start:p.pos]
+
+	if tomlDate.MatchString(token) && strings.HasPrefix(p.s[p.pos:], " ") &&
+		p.pos+1 < len(p.s) && p.s[p.pos+1] >= '0' && p.s[p.pos+1] <= '9' {
+		p.pos++
+		for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.peek())) {
+			p.pos++
+		}
+		return p.s[start:p.pos], nil
+	}
+
+	return tomlScalar(token)
+}
+
+func tomlScalar(token string) (any, error) {
+	switch token {
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+
+	num := strings.ReplaceAll(token, "_", "")
+	switch {
+	case tomlPrefixed.MatchString(token):
+		return tomlInteger(num, 0)
+	case tomlDecimal.MatchString(token):
+		return tomlInteger(num, 10)
+	case tomlFloatNumber.MatchString(token):
+		return strconv.ParseFloat(num, 64)
+	case token != "" && strings.ContainsAny(token, "-:") && token[0] >= '0' && token[0] <= '9':
+		return token, nil
+	case tomlLeadingZero.MatchString(token):
+		return nil, fmt.Errorf("invalid value %q: leading zeros are not allowed", token)
+	}
+	return nil, fmt.Errorf("invalid value %q", token)
+}
+
+func tomlInteger(num string, base int) (any, error) {
+	i, err := strconv.ParseInt(num, base, 64)
+	if err != nil {
+		return nil, fmt.Errorf("integer %q is out of range", num)
+	}
+	return autoParseString(strconv.FormatInt(i, 10)), nil
+}
+
+func (p *tomlParser) parseString() (string, error) {
+	quote := p.peek()
+	multi := strings.Repeat(string(quote), 3)
+	if strings.HasPrefix(p.s[p.pos:], multi) {
+		return p.parseMultilineString(quote, multi)
+	}
+
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", errors.New("unterminated string")
+		}
+		c := p.peek()
+		p.pos++
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\\' && quote == '"':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+func (p *tomlParser) parseMultilineString(quote byte, multi string) (string, error) {
+	p.pos += 3
+	if strings.HasPrefix(p.s[p.pos:], "\r\n") {
+		p.pos += 2
+	} else if !p.eof() && p.peek() == '\n' {
+		p.pos++
+	}
+
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", errors.New("unterminated multi-line string")
+		}
+		if strings.HasPrefix(p.s[p.pos:], multi) {
+			p.pos += 3
+			for extra := 0; extra < 2 && !p.eof() && p.peek() == quote; extra++ {
+				b.WriteByte(quote)
+				p.pos++
+			}
+			return b.String(), nil
+		}
+
+		c := p.peek()
+		p.pos++
+		switch {
+		case c == '\n':
+			p.line++
+			b.WriteByte(c)
+		case c == '\\' && quote == '"':
+			if p.trimLineEnding() {
+				continue
+			}
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+func (p *tomlParser) trimLineEnding() bool {
+	i := p.pos
+	for i < len(p.s) && (p.s[i] == ' ' || p.s[i] == '\t') {
+		i++
+	}
+	if i >= len(p.s) || (p.s[i] != '\n' && p.s[i] != '\r') {
+		return false
+	}
+	for i < len(p.s) && strings.ContainsRune(" \t\r\n", rune(p.s[i])) {
+		if p.s[i] == '\n' {
+			p.line++
+		}
+		i++
+	}
+	p.pos = i
+	return true
+}
+
+func (p *tomlParser) parseEscape(b *strings.Builder) error {
+	if p.eof() {
+		return errors.New("unterminated escape sequence")
+	}
+	c := p.peek()
+	p.pos++
+
+	simple := map[byte]byte{'b': '\b', 't': '\t', 'n': '\n', 'f': '\f', 'r': '\r', '"': '"', '\\': '\\'}
+	if r, ok := simple[c]; ok {
+		b.WriteByte(r)
+		return nil
+	}
+
+	size := map[byte]int{'u': 4, 'U': 8}[c]
+	if size == 0 || p.pos+size > len(p.s) {
+		return fmt.Errorf("invalid escape sequence \\%c", c)
+	}
+	code, err := strconv.ParseUint(p.s[p.pos:p.pos+size], 16, 32)
+	if err != nil {
+		return fmt.Errorf("invalid unicode escape \\%c%s", c, p.s[p.pos:p.pos+size])
+	}
+	p.pos += size
+	b.WriteRune(rune(code))
+	return nil
+}
+
+func (p *tomlParser) skipBlank(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t':
+			p.pos++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		case newlines && (c == '\n' || c == '\r'):
+			if c == '\n' {
+				p.line++
+			}
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func isTOMLBareChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) peek() byte { return p.s[p.pos] }
+
+func (p *tomlParser) eof() bool { return p.pos >= len(p.s) }
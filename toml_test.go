@@ -0,0 +1,284 @@
+package config
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTOMLValue(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		in   any
+		want string
+	}{
+		{"a\"b\\c\n\x01", `"a\"b\\c\n\u0001"`},
+		{true, "true"},
+		{42, "42"},
+		{int64(-7), "-7"},
+		{uint64(7), "7"},
+		{1.0, "1.0"},
+		{2.5, "2.5"},
+		{1e21, "1e+21"},
+		{math.NaN(), "nan"},
+		{math.Inf(1), "inf"},
+		{math.Inf(-1), "-inf"},
+		{[]any{1, "x", nil}, `[1, "x"]`},
+		{[]any{map[string]any{"b": 1, "a": "x"}, 2}, `[{a = "x", b = 1}, 2]`},
+	}
+	for _, tc := range cases {
+		got, err := tomlValue(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("tomlValue(%#v) = %q, %v; want %q", tc.in, got, err, tc.want)
+		}
+	}
+}
+
+func TestEncodeTOML_NestedTables(t *testing.T) {
+	t.Parallel()
+	var buf strings.Builder
+	err := encodeTOML(&buf, map[string]any{
+		"title": "x",
+		"a.b":   map[string]any{"c": map[string]any{"d": 1}},
+		"skip":  nil,
+	}, func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "title = \"x\"\n\n[\"a.b\"]\n\n[\"a.b\".c]\nd = 1\n"
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestParseTOML(t *testing.T) {
+	t.Parallel()
+	data := `
+# comment
+title = "demo" # trailing
+"quoted key" = 'C:\path'
+site.owner = "ops"
+hex = 0xff
+big = 1_000
+ratio = 1.5e2
+neg_inf = -inf
+dob = 1979-05-27T07:32:00Z
+spaced = 1979-05-27 07:32:00
+day = 1979-05-27
+escaped = "tab\tquote\" \u00e9"
+multi = """
+first \
+  second"""
+literal = '''
+raw\n'''
+list = [
+  1,
+  2, # comment
+]
+inline = { host = "a", port = 1 }
+
+[server]
+host = "localhost"
+
+[server.tls]
+enabled = true
+
+[[servers]]
+name = "a"
+
+[[servers]]
+name = "b"
+
+[servers.meta]
+zone = "eu"
+`
+	m, err := parseTOML([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg := FromMap(m)
+	checks := map[string]any{
+		"title":              "demo",
+		"quoted key":         `C:\path`,
+		"site.owner":         "ops",
+		"hex":                255,
+		"big":                1000,
+		"ratio":              150.0,
+		"dob":                "1979-05-27T07:32:00Z",
+		"spaced":             "1979-05-27 07:32:00",
+		"day":                "1979-05-27",
+		"escaped":            "tab\tquote\" é",
+		"multi":              "first second",
+		"literal":            `raw\n`,
+		"inline.port":        1,
+		"server.host":        "localhost",
+		"server.tls.enabled": true,
+	}
+	for key, want := range checks {
+		if got := cfg.Get(key); got != want {
+			t.Errorf("%s: expected %#v, got %#v", key, want, got)
+		}
+	}
+	if !math.IsInf(cfg.GetFloat64("neg_inf"), -1) {
+		t.Errorf("expected -inf, got %v", cfg.Get("neg_inf"))
+	}
+	if ints := cfg.GetIntSlice("list"); len(ints) != 2 || ints[1] != 2 {
+		t.Errorf("unexpected list: %v", cfg.Get("list"))
+	}
+	servers := cfg.Get("servers").([]any)
+	if len(servers) != 2 || servers[1].(map[string]any)["meta"].(map[string]any)["zone"] != "eu" {
+		t.Errorf("unexpected servers: %v", servers)
+	}
+}
+
+func TestParseTOML_Errors(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		"missing equals": "key value",
+		"duplicate":      "a = 1\na = 2",
+		"unterminated":   `a = "abc`,
+		"bad value":      "a = nope",
+		"bad header":     "[a",
+		"trailing":       "a = 1 2",
+		"not a table":    "a = 1\n[a.b]",
+		"bad escape":     `a = "\q"`,
+		"open array":     "a = [1, 2",
+	}
+	for name, data := range cases {
+		if _, err := parseTOML([]byte(data)); !errors.Is(err, ErrParseTOML) {
+			t.Errorf("%s: expected ErrParseTOML, got %v", name, err)
+		}
+	}
+	_, err := parseTOML([]byte("a = 1\n\nb = ?"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected line number in error, got %v", err)
+	}
+}
+
+func TestParseTOML_SpecValid(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		data string
+		want map[string]any
+	}{
+		"super table after sub table": {
+			"[x.y.z.w]\na = 1\n[x]\nb = 2",
+			map[string]any{"x": map[string]any{"b": 2, "y": map[string]any{"z": map[string]any{"w": map[string]any{"a": 1}}}}},
+		},
+		"dotted keys extend each other": {
+			"apple.type = \"fruit\"\norange.type = \"fruit\"\napple.skin = \"thin\"",
+			map[string]any{
+				"apple":  map[string]any{"type": "fruit", "skin": "thin"},
+				"orange": map[string]any{"type": "fruit"},
+			},
+		},
+		"sub table under dotted table": {
+			"[fruit]\napple.color = \"red\"\n[fruit.apple.texture]\nsmooth = true",
+			map[string]any{"fruit": map[string]any{"apple": map[string]any{
+				"color": "red", "texture": map[string]any{"smooth": true},
+			}}},
+		},
+		"dotted keys in array of tables": {
+			"[[a]]\nb.c = 1\n[[a]]\nb.c = 2",
+			map[string]any{"a": []any{
+				map[string]any{"b": map[string]any{"c": 1}},
+				map[string]any{"b": map[string]any{"c": 2}},
+			}},
+		},
+		"sub table of array element": {
+			"[[fruits]]\nname = \"apple\"\n[fruits.physical]\ncolor = \"red\"\n[[fruits]]\nname = \"banana\"",
+			map[string]any{"fruits": []any{
+				map[string]any{"name": "apple", "physical": map[string]any{"color": "red"}},
+				map[string]any{"name": "banana"},
+			}},
+		},
+		"quoted dots are not separators": {
+			"[\"a.b\"]\nc = 1\n[a]\nb = 2",
+			map[string]any{"a.b": map[string]any{"c": 1}, "a": map[string]any{"b": 2}},
+		},
+		"integers": {
+			"a = 0\nb = +0\nc = -17\nd = 1_000\ne = 0xdead_beef\nf = 0o755\ng = 0b1101",
+			map[string]any{"a": 0, "b": 0, "c": -17, "d": 1000, "e": 3735928559, "f": 493, "g": 13},
+		},
+		"floats": {
+			"a = 0.5\nb = -0.0\nc = 5e+22\nd = 1e06\ne = 6.626e-34\nf = 224_617.445_991",
+			map[string]any{"a": 0.5, "b": math.Copysign(0, -1), "c": 5e22, "d": 1e6, "e": 6.626e-34, "f": 224617.445991},
+		},
+		"inline table with dotted keys": {
+			"a = { b.c = 1, b.d = 2 }",
+			map[string]any{"a": map[string]any{"b": map[string]any{"c": 1, "d": 2}}},
+		},
+	}
+	for name, tc := range cases {
+		got, err := parseTOML([]byte(tc.data))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s:\nexpected %#v\ngot      %#v", name, tc.want, got)
+		}
+	}
+}
+
+func TestParseTOML_SpecInvalid(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		data string
+		want string
+	}{
+		"duplicate table":            {"[a]\nb = 1\n[a]\nc = 2", `table "a" is already defined`},
+		"duplicate sub table":        {"[a.b]\n[a]\n[a.b]", `table "a.b" is already defined`},
+		"table redefines key":        {"a = 1\n[a]", `table "a" is already defined`},
+		"table after dotted key":     {"a.b = 1\n[a]", `table "a" is already defined`},
+		"dotted table redefined":     {"[fruit]\napple.color = \"red\"\n[fruit.apple]", `table "fruit.apple" is already defined`},
+		"dotted key extends table":   {"[a.b.c]\nz = 9\n[a]\nb.c.t = 1", `cannot extend table "b" with dotted keys`},
+		"dotted key extends inline":  {"a = { b = 1 }\na.c = 2", `cannot extend table "a" with dotted keys`},
+		"table extends inline":       {"a = { b = 1 }\n[a.c]", `cannot extend table "a" with dotted keys`},
+		"inline dotted redefinition": {"a = { b = { c = 1 }, b.d = 2 }", `cannot extend table "b" with dotted keys`},
+		"table array after table":    {"[a]\n[[a]]", `key "a" is not an array of tables`},
+		"table array after array":    {"a = []\n[[a]]", `key "a" is not an array of tables`},
+		"table in static array":      {"a = [{ b = 1 }]\n[a.c]", `key "a" is not a table`},
+		"leading zero":               {"a = 007", `invalid value "007": leading zeros are not allowed`},
+		"signed leading zero":        {"a = -01", "leading zeros are not allowed"},
+		"leading zero float":         {"a = 01.5", "leading zeros are not allowed"},
+		"double underscore":          {"a = 1__000", `invalid value "1__000"`},
+		"trailing underscore":        {"a = 1_", `invalid value "1_"`},
+		"signed hex":                 {"a = +0xff", `invalid value "+0xff"`},
+		"bare fraction":              {"a = .5", `invalid value ".5"`},
+		"trailing dot":               {"a = 1.", `invalid value "1."`},
+		"integer overflow":           {"a = 9223372036854775808", "out of range"},
+	}
+	for name, tc := range cases {
+		_, err := parseTOML([]byte(tc.data))
+		if !errors.Is(err, ErrParseTOML) || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected error containing %q, got %v", name, tc.want, err)
+		}
+	}
+}
+
+func TestTOML_RoundTrip(t *testing.T) {
+	t.Parallel()
+	in := map[string]any{
+		"name":    "demo \"x\"",
+		"port":    8080,
+		"ratio":   0.5,
+		"tags":    []any{"a", "b"},
+		"server":  map[string]any{"tls": map[string]any{"enabled": true}},
+		"servers": []any{map[string]any{"host": "a"}, map[string]any{"host": "b"}},
+	}
+	var buf strings.Builder
+	if err := encodeTOML(&buf, in, func(string) string { return "" }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := parseTOML([]byte(buf.String()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, buf.String())
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip mismatch:\n%v\n%v", in, out)
+	}
+}
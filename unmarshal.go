@@ -1,9 +1,12 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -15,6 +18,23 @@ var (
 )
 
 func (c *Config) Unmarshal(key string, target any) error {
+	return c.unmarshal(key, target, c.newDecoder())
+}
+
+func (c *Config) UnmarshalStrict(key string, target any) error {
+	d := c.newDecoder()
+	d.strict = true
+	if err := c.unmarshal(key, target, d); err != nil {
+		return err
+	}
+	if len(d.unknown) > 0 {
+		slices.SortFunc(d.unknown, func(a, b UnknownKey) int { return strings.Compare(a.Path, b.Path) })
+		return &UnknownKeysError{Keys: d.unknown}
+	}
+	return nil
+}
+
+func (c *Config) unmarshal(key string, target any, d *decoder) error {
 	rv := reflect.ValueOf(target)
 	if rv.Kind() != reflect.Pointer || rv.IsNil() {
 		return fmt.Errorf("config: unmarshal target must be a non-nil pointer to struct")
@@ -40,93 +60,344 @@ func (c *Config) Unmarshal(key string, target any) error {
 		values = m
 	}
 
-	return unmarshalStruct(values, elem)
+	at := fieldPath{key: key}
+	err := d.unmarshalStruct(values, elem, at)
+
+	d.runHooks(elem, at)
+
+	if len(d.violations) > 0 {
+		return errors.Join(err, newValidationError(d.violations))
+	}
+	return err
 }
 
-func unmarshalStruct(values map[string]any, rv reflect.Value) error {
-	rt := rv.Type()
+type decoder struct {
+	strict     bool
+	unknown    []UnknownKey
+	violations []error
+	sensitive  *sensitiveKeys
+	sources    sourceMap
+	naming     NameMapper
+	foldCase   bool
+}
+
+func (c *Config) newDecoder() *decoder {
+	return &decoder{sensitive: c.sensitive, sources: c.sources, naming: c.naming, foldCase: c.foldCase}
+}
+
+type fieldPath struct {
+	key   string
+	field string
+}
+
+func (p fieldPath) child(key, field string) fieldPath {
+	return fieldPath{key: joinKey(p.key, key), field: joinKey(p.field, field)}
+}
+
+func (p fieldPath) embed(field string) fieldPath {
+	return fieldPath{key: p.key, field: joinKey(p.field, field)}
+}
+
+func (p fieldPath) index(i int) fieldPath {
+	return fieldPath{key: indexKey(p.key, i), field: indexKey(p.field, i)}
+}
+
+func (p fieldPath) mapKey(k string) fieldPath {
+	return fieldPath{key: joinKey(p.key, k), field: fmt.Sprintf("%s[%q]", p.field, k)}
+}
+
+func (d *decoder) fieldErrors(err error, at fieldPath, t reflect.Type, tag reflect.StructTag, val any) []FieldError {
+	var errs []FieldError
+
+	var unmarshalErr *UnmarshalError
+	if errors.As(err, &unmarshalErr) {
+		errs = unmarshalErr.Errors
+	} else {
+		errs = []FieldError{{
+			Path:   at.key,
+			Field:  at.field,
+			Type:   t.String(),
+			Value:  val,
+			Source: d.sources.lookup(at.key),
+			Err:    err,
+		}}
+	}
+
+	if !d.isSensitive(t, tag, at.key) {
+		return errs
+	}
+
+	for i := range errs {
+		if _, ok := errs[i].Err.(redactedError); ok {
+			continue
+		}
+		errs[i].Value = redactedValue
+		errs[i].Err = redactedError{err: errs[i].Err}
+	}
+	return errs
+}
+
+func (d *decoder) isSensitive(t reflect.Type, tag reflect.StructTag, key string) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return isSecretType(t) || tag.Get("secret") == "true" || d.sensitive.matches(key)
+}
+
+func (d *decoder) unmarshalStruct(values map[string]any, rv reflect.Value, at fieldPath) error {
+	if errs := d.unmarshalFields(values, rv, at); len(errs) > 0 {
+		return &UnmarshalError{Errors: errs}
+	}
+	if d.strict {
+		d.collectUnknown(values, rv.Type(), at.key)
+	}
+	return nil
+}
 
+func (d *decoder) unmarshalFields(values map[string]any, rv reflect.Value, at fieldPath) []FieldError {
+	var errs []FieldError
+
+	rt := rv.Type()
 	for i := 0; i < rt.NumField(); i++ {
 		field := rt.Field(i)
 		fieldVal := rv.Field(i)
 
-		if !fieldVal.CanSet() {
+		tag, ok := parseFieldTag(field, d.naming)
+		if !ok {
 			continue
 		}
 
-		tag := field.Tag.Get("cfg")
-		if tag == "-" {
+		if tag.squash {
+			errs = append(errs, d.unmarshalSquashed(values, fieldVal, at.embed(field.Name))...)
 			continue
 		}
-		if tag == "" {
-			tag = strings.ToLower(field.Name)
+
+		if !fieldVal.CanSet() {
+			continue
 		}
 
-		val, exists := values[tag]
+		fieldAt := at.child(tag.name, field.Name)
+		val, exists := lookupKey(values, tag.name, d.foldCase)
 
 		if !exists || val == nil {
-			if err := applyDefault(field, fieldVal); err != nil {
-				return err
+			n := len(d.violations)
+			if isNestedStruct(field.Type) {
+				if err := d.unmarshalStruct(map[string]any{}, fieldVal, fieldAt); err != nil {
+					errs = append(errs, d.fieldErrors(err, fieldAt, field.Type, field.Tag, nil)...)
+				}
+			}
+			if tag.has("required") {
+				d.violations = append(d.violations[:n], missingKeyError(fieldAt.key))
+			}
+			if err := d.applyDefault(field, fieldVal); err != nil {
+				errs = append(errs, d.defaultError(err, fieldAt, field))
 			}
 			continue
 		}
 
-		ft := field.Type
-		if ft.Kind() == reflect.Pointer {
-			ft = ft.Elem()
+		if err := d.unmarshalField(field, fieldVal, val, fieldAt); err != nil {
+			errs = append(errs, d.fieldErrors(err, fieldAt, field.Type, field.Tag, val)...)
+			continue
 		}
+		d.validateField(field, val, fieldAt)
+	}
+
+	return errs
+}
+
+func (d *decoder) unmarshalField(field reflect.StructField, fieldVal reflect.Value, val any, at fieldPath) error {
+	ft := field.Type
+	if ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+
+	if isNestedStruct(ft) {
+		return d.unmarshalNestedStruct(field, fieldVal, ft, val, at)
+	}
+
+	converted, err := d.convertToType(val, field.Type, field.Tag, at)
+	if err != nil {
+		return err
+	}
+	fieldVal.Set(converted)
+	return nil
+}
 
-		if ft.Kind() == reflect.Struct && ft != timeType && ft != durationType {
-			if err := unmarshalNestedStruct(field, fieldVal, ft, val); err != nil {
-				return err
+func (d *decoder) validateField(field reflect.StructField, val any, at fieldPath) {
+	spec := field.Tag.Get("validate")
+	if spec == "" {
+		return
+	}
+
+	sensitive := d.isSensitive(field.Type, field.Tag, at.key)
+
+	for _, err := range checkTag(at.key, val, spec) {
+		v := asViolation(err)
+		v.Source = d.sources.lookup(at.key)
+		if sensitive {
+			v.Value = redactedValue
+			v.Message = fmt.Sprintf("sensitive value failed validation %q", spec)
+		}
+		d.violations = append(d.violations, v)
+	}
+}
+
+func (d *decoder) defaultError(err error, at fieldPath, field reflect.StructField) FieldError {
+	return FieldError{
+		Path:   at.key,
+		Field:  at.field,
+		Type:   field.Type.String(),
+		Value:  field.Tag.Get("default"),
+		Source: "default",
+		Err:    err,
+	}
+}
+
+func (d *decoder) collectUnknown(values map[string]any, t reflect.Type, path string) {
+	known := structKeys(t, d.naming)
+
+	for k, v := range values {
+		if d.isKnown(k, known) {
+			continue
+		}
+
+		suggestion := nearestKey(k, known)
+		leaves := make(map[string]any)
+		flattenValue(k, v, leaves)
+
+		for leaf := range leaves {
+			unknown := UnknownKey{Path: joinKey(path, leaf)}
+			if suggestion != "" {
+				unknown.Suggestion = joinKey(path, suggestion+strings.TrimPrefix(leaf, k))
 			}
+			d.unknown = append(d.unknown, unknown)
+		}
+	}
+}
+
+func (d *decoder) isKnown(key string, known []string) bool {
+	if !d.foldCase {
+		return slices.Contains(known, key)
+	}
+	return slices.ContainsFunc(known, func(k string) bool { return strings.EqualFold(k, key) })
+}
+
+func structKeys(t reflect.Type, naming NameMapper) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := parseFieldTag(field, naming)
+		if !ok {
+			continue
+		}
+		if tag.squash {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			keys = append(keys, structKeys(ft, naming)...)
 			continue
 		}
+		keys = append(keys, tag.name)
+	}
+	return keys
+}
 
-		converted, err := convertToType(val, field.Type, field.Tag)
-		if err != nil {
-			return fmt.Errorf("field %s: %w", field.Name, err)
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !hasCustomDecoder(t)
+}
+
+type fieldTag struct {
+	name    string
+	squash  bool
+	options []string
+}
+
+func (t fieldTag) has(option string) bool {
+	for _, o := range t.options {
+		if o == option {
+			return true
 		}
-		fieldVal.Set(converted)
 	}
+	return false
+}
 
-	return nil
+func parseFieldTag(field reflect.StructField, naming NameMapper) (fieldTag, bool) {
+	raw := field.Tag.Get("cfg")
+	if raw == "-" {
+		return fieldTag{}, false
+	}
+
+	parts := strings.Split(raw, ",")
+	tag := fieldTag{name: parts[0], options: parts[1:]}
+
+	ft := field.Type
+	if ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+	if isNestedStruct(ft) {
+		tag.squash = tag.has("squash") || (field.Anonymous && tag.name == "")
+	}
+
+	if tag.name == "" {
+		tag.name = naming.name(field.Name)
+	}
+
+	if !field.IsExported() && !tag.squash {
+		return fieldTag{}, false
+	}
+
+	return tag, true
 }
 
-func applyDefault(field reflect.StructField, fieldVal reflect.Value) error {
+func (d *decoder) unmarshalSquashed(values map[string]any, fieldVal reflect.Value, at fieldPath) []FieldError {
+	if fieldVal.Kind() != reflect.Pointer {
+		return d.unmarshalFields(values, fieldVal, at)
+	}
+
+	if !fieldVal.CanSet() {
+		return nil
+	}
+	if fieldVal.IsNil() {
+		fieldVal.Set(reflect.New(fieldVal.Type().Elem()))
+	}
+	return d.unmarshalFields(values, fieldVal.Elem(), at)
+}
+
+func (d *decoder) applyDefault(field reflect.StructField, fieldVal reflect.Value) error {
 	defaultStr, ok := field.Tag.Lookup("default")
 	if !ok {
 		return nil
 	}
-	parsed, err := parseStringToType(defaultStr, field.Type, field.Tag)
+	parsed, err := d.parseStringToType(defaultStr, field.Type, field.Tag)
 	if err != nil {
-		return fmt.Errorf("field %s: invalid default %q: %w", field.Name, defaultStr, err)
+		return fmt.Errorf("invalid default: %w", err)
 	}
 	fieldVal.Set(parsed)
 	return nil
 }
 
-func unmarshalNestedStruct(field reflect.StructField, fieldVal reflect.Value, ft reflect.Type, val any) error {
+func (d *decoder) unmarshalNestedStruct(field reflect.StructField, fieldVal reflect.Value, ft reflect.Type, val any, at fieldPath) error {
 	subMap, ok := val.(map[string]any)
 	if !ok {
-		return fmt.Errorf("field %s: expected map, got %T", field.Name, val)
+		return fmt.Errorf("expected map, got %T", val)
 	}
 
 	if field.Type.Kind() == reflect.Pointer {
 		ptr := reflect.New(ft)
-		if err := unmarshalStruct(subMap, ptr.Elem()); err != nil {
+		if err := d.unmarshalStruct(subMap, ptr.Elem(), at); err != nil {
 			return err
 		}
 		fieldVal.Set(ptr)
 		return nil
 	}
 
-	return unmarshalStruct(subMap, fieldVal)
+	return d.unmarshalStruct(subMap, fieldVal, at)
 }
 
-func convertToType(val any, t reflect.Type, tag reflect.StructTag) (reflect.Value, error) {
+func (d *decoder) convertToType(val any, t reflect.Type, tag reflect.StructTag, at fieldPath) (reflect.Value, error) {
 	if t.Kind() == reflect.Pointer {
-		inner, err := convertToType(val, t.Elem(), tag)
+		inner, err := d.convertToType(val, t.Elem(), tag, at)
 		if err != nil {
 			return reflect.Value{}, err
 		}
@@ -135,16 +406,8 @@ func convertToType(val any, t reflect.Type, tag reflect.StructTag) (reflect.Valu
 		return ptr, nil
 	}
 
-	if t == durationType {
-		return convertToDuration(val)
-	}
-
-	if t == timeType {
-		layout := tag.Get("layout")
-		if layout == "" {
-			layout = time.RFC3339
-		}
-		return convertToTime(val, layout)
+	if converted, ok, err := d.convertSpecial(val, t, tag, at); ok {
+		return converted, err
 	}
 
 	switch t.Kind() {
@@ -168,16 +431,50 @@ func convertToType(val any, t reflect.Type, tag reflect.StructTag) (reflect.Valu
 		return convertToFloat(val, t)
 
 	case reflect.Slice:
-		return convertToSlice(val, t, tag)
+		return d.convertToSlice(val, t, tag, at)
 
 	case reflect.Map:
-		return convertToMap(val, t)
+		return d.convertToMap(val, t, at)
+
+	case reflect.Struct:
+		return d.convertToStruct(val, t, at)
 
 	default:
 		return reflect.Value{}, fmt.Errorf("unsupported type %s", t)
 	}
 }
 
+func (d *decoder) convertSpecial(val any, t reflect.Type, tag reflect.StructTag, at fieldPath) (reflect.Value, bool, error) {
+	if decode, ok := lookupDecoder(t); ok {
+		converted, err := decode(val)
+		return converted, true, err
+	}
+
+	if isSecretType(t) {
+		ptr := reflect.New(t)
+		if err := ptr.Interface().(configDecoder).decodeConfig(d, val, tag, at); err != nil {
+			return reflect.Value{}, true, err
+		}
+		return ptr.Elem(), true, nil
+	}
+
+	if t == durationType {
+		converted, err := convertToDuration(val)
+		return converted, true, err
+	}
+
+	if t == timeType {
+		layout := tag.Get("layout")
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		converted, err := convertToTime(val, layout)
+		return converted, true, err
+	}
+
+	return convertWithUnmarshaler(val, t)
+}
+
 func convertToDuration(val any) (reflect.Value, error) {
 	switch v := val.(type) {
 	case string:
@@ -314,59 +611,56 @@ func convertToFloat(val any, t reflect.Type) (reflect.Value, error) {
 	return rv, nil
 }
 
-func convertToSlice(val any, t reflect.Type, tag reflect.StructTag) (reflect.Value, error) {
-	elemType := t.Elem()
+func (d *decoder) convertToSlice(val any, t reflect.Type, tag reflect.StructTag, at fieldPath) (reflect.Value, error) {
+	var items []any
 
-	switch items := val.(type) {
+	switch v := val.(type) {
 	case []any:
-		slice := reflect.MakeSlice(t, 0, len(items))
-		for i, item := range items {
-			converted, err := convertToType(item, elemType, tag)
-			if err != nil {
-				return reflect.Value{}, fmt.Errorf("index %d: %w", i, err)
-			}
-			slice = reflect.Append(slice, converted)
+		items = v
+	case map[string]any:
+		var ok bool
+		if items, ok = indexedItems(v); !ok {
+			items = []any{val}
 		}
-		return slice, nil
-
 	case []string:
-		slice := reflect.MakeSlice(t, 0, len(items))
-		for i, item := range items {
-			converted, err := convertToType(item, elemType, tag)
-			if err != nil {
-				return reflect.Value{}, fmt.Errorf("index %d: %w", i, err)
-			}
-			slice = reflect.Append(slice, converted)
+		items = make([]any, len(v))
+		for i, item := range v {
+			items[i] = item
 		}
-		return slice, nil
-
 	case string:
 		sep := ","
 		if s := tag.Get("separator"); s != "" {
 			sep = s
 		}
-		parts := strings.Split(items, sep)
-		slice := reflect.MakeSlice(t, 0, len(parts))
+		parts := strings.Split(v, sep)
+		items = make([]any, len(parts))
 		for i, part := range parts {
-			converted, err := convertToType(strings.TrimSpace(part), elemType, tag)
-			if err != nil {
-				return reflect.Value{}, fmt.Errorf("index %d: %w", i, err)
-			}
-			slice = reflect.Append(slice, converted)
+			items[i] = strings.TrimSpace(part)
 		}
-		return slice, nil
-
 	default:
-		converted, err := convertToType(val, elemType, tag)
+		items = []any{val}
+	}
+
+	elemType := t.Elem()
+	slice := reflect.MakeSlice(t, 0, len(items))
+
+	var errs []FieldError
+	for i, item := range items {
+		converted, err := d.convertToType(item, elemType, tag, at.index(i))
 		if err != nil {
-			return reflect.Value{}, err
+			errs = append(errs, d.fieldErrors(err, at.index(i), elemType, tag, item)...)
+			continue
 		}
-		slice := reflect.MakeSlice(t, 0, 1)
-		return reflect.Append(slice, converted), nil
+		slice = reflect.Append(slice, converted)
 	}
+
+	if len(errs) > 0 {
+		return reflect.Value{}, &UnmarshalError{Errors: errs}
+	}
+	return slice, nil
 }
 
-func convertToMap(val any, t reflect.Type) (reflect.Value, error) {
+func (d *decoder) convertToMap(val any, t reflect.Type, at fieldPath) (reflect.Value, error) {
 	m, ok := val.(map[string]any)
 	if !ok {
 		return reflect.Value{}, fmt.Errorf("cannot convert %T to %s", val, t)
@@ -380,18 +674,37 @@ func convertToMap(val any, t reflect.Type) (reflect.Value, error) {
 	}
 
 	result := reflect.MakeMapWithSize(t, len(m))
-	for k, v := range m {
-		converted, err := convertToType(v, valType, "")
+
+	var errs []FieldError
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		converted, err := d.convertToType(m[k], valType, "", at.mapKey(k))
 		if err != nil {
-			return reflect.Value{}, fmt.Errorf("map key %q: %w", k, err)
+			errs = append(errs, d.fieldErrors(err, at.mapKey(k), valType, "", m[k])...)
+			continue
 		}
 		result.SetMapIndex(reflect.ValueOf(k), converted)
 	}
 
+	if len(errs) > 0 {
+		return reflect.Value{}, &UnmarshalError{Errors: errs}
+	}
 	return result, nil
 }
 
-func parseStringToType(s string, t reflect.Type, tag reflect.StructTag) (reflect.Value, error) {
+func (d *decoder) convertToStruct(val any, t reflect.Type, at fieldPath) (reflect.Value, error) {
+	m, ok := val.(map[string]any)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("cannot convert %T to %s", val, t)
+	}
+
+	rv := reflect.New(t).Elem()
+	if err := d.unmarshalStruct(m, rv, at); err != nil {
+		return reflect.Value{}, err
+	}
+	return rv, nil
+}
+
+func (d *decoder) parseStringToType(s string, t reflect.Type, tag reflect.StructTag) (reflect.Value, error) {
 	if t == durationType {
 		d, err := time.ParseDuration(s)
 		if err != nil {
@@ -411,7 +724,7 @@ func parseStringToType(s string, t reflect.Type, tag reflect.StructTag) (reflect
 		return reflect.ValueOf(parsed), nil
 	}
 
-	return convertToType(s, t, tag)
+	return d.convertToType(s, t, tag, fieldPath{})
 }
 
 func toBool(v any) (bool, bool) {
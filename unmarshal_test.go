@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"math"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -136,6 +138,39 @@ func TestUnmarshal_Defaults(t *testing.T) {
 	}
 }
 
+type missingParentTarget struct {
+	DB struct {
+		Host string `cfg:"host,required"`
+		Pool struct {
+			Size int `cfg:"size" default:"4"`
+		} `cfg:"pool"`
+	} `cfg:"db"`
+	Cache struct {
+		Addr string `cfg:"addr,required"`
+	} `cfg:"cache,required"`
+	Backup *struct {
+		Path string `cfg:"path,required"`
+	} `cfg:"backup"`
+}
+
+func TestUnmarshal_MissingParentStruct(t *testing.T) {
+	t.Parallel()
+	var target missingParentTarget
+	err := newTestConfig(map[string]any{}).Unmarshal("", &target)
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	want := []string{`"db.host": required key is missing`, `"cache": required key is missing`}
+	if !slices.Equal(ve.Violations, want) {
+		t.Errorf("unexpected violations:\n%s", strings.Join(ve.Violations, "\n"))
+	}
+	if target.DB.Pool.Size != 4 || target.Backup != nil {
+		t.Errorf("unexpected target: %+v", target)
+	}
+}
+
 type badDefaultTarget struct {
 	Port int `cfg:"port" default:"abc"`
 }
@@ -382,7 +417,7 @@ func TestConvertToTime_Branches(t *testing.T) {
 func TestConvertToSlice_StringInput(t *testing.T) {
 	t.Parallel()
 	st := reflect.TypeOf([]string{})
-	v, err := convertToSlice("a,b,c", st, ``)
+	v, err := (&decoder{}).convertToSlice("a,b,c", st, ``, fieldPath{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -395,7 +430,7 @@ func TestConvertToSlice_StringInput(t *testing.T) {
 func TestConvertToSlice_StringSliceInput(t *testing.T) {
 	t.Parallel()
 	st := reflect.TypeOf([]string{})
-	v, err := convertToSlice([]string{"x", "y"}, st, ``)
+	v, err := (&decoder{}).convertToSlice([]string{"x", "y"}, st, ``, fieldPath{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -408,7 +443,7 @@ func TestConvertToSlice_StringSliceInput(t *testing.T) {
 func TestConvertToSlice_SingleValue(t *testing.T) {
 	t.Parallel()
 	st := reflect.TypeOf([]string{})
-	v, err := convertToSlice(42, st, ``)
+	v, err := (&decoder{}).convertToSlice(42, st, ``, fieldPath{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -421,7 +456,7 @@ func TestConvertToSlice_SingleValue(t *testing.T) {
 func TestConvertToSlice_SingleVal_Error(t *testing.T) {
 	t.Parallel()
 	st := reflect.TypeOf([]int{})
-	_, err := convertToSlice(complex(1, 2), st, ``)
+	_, err := (&decoder{}).convertToSlice(complex(1, 2), st, ``, fieldPath{})
 	if err == nil {
 		t.Error("expected error for unsupported single value conversion")
 	}
@@ -430,7 +465,7 @@ func TestConvertToSlice_SingleVal_Error(t *testing.T) {
 func TestConvertToMap_NonMapInput(t *testing.T) {
 	t.Parallel()
 	mt := reflect.TypeOf(map[string]string{})
-	_, err := convertToMap("notmap", mt)
+	_, err := (&decoder{}).convertToMap("notmap", mt, fieldPath{})
 	if err == nil {
 		t.Error("expected error for non-map input")
 	}
@@ -439,7 +474,7 @@ func TestConvertToMap_NonMapInput(t *testing.T) {
 func TestConvertToMap_NonStringKey(t *testing.T) {
 	t.Parallel()
 	mt := reflect.MapOf(reflect.TypeOf(0), reflect.TypeOf(""))
-	_, err := convertToMap(map[string]any{"k": "v"}, mt)
+	_, err := (&decoder{}).convertToMap(map[string]any{"k": "v"}, mt, fieldPath{})
 	if err == nil {
 		t.Error("expected error for non-string key type")
 	}
@@ -448,7 +483,7 @@ func TestConvertToMap_NonStringKey(t *testing.T) {
 func TestConvertToType_Ptr(t *testing.T) {
 	t.Parallel()
 	pt := reflect.TypeOf((*string)(nil))
-	v, err := convertToType("hello", pt, "")
+	v, err := (&decoder{}).convertToType("hello", pt, "", fieldPath{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -460,7 +495,7 @@ func TestConvertToType_Ptr(t *testing.T) {
 func TestConvertToType_UnsupportedType(t *testing.T) {
 	t.Parallel()
 	ct := reflect.TypeOf(make(chan int))
-	_, err := convertToType("val", ct, "")
+	_, err := (&decoder{}).convertToType("val", ct, "", fieldPath{})
 	if err == nil {
 		t.Error("expected error for unsupported type")
 	}
@@ -469,7 +504,7 @@ func TestConvertToType_UnsupportedType(t *testing.T) {
 func TestConvertToType_Bool_BadType(t *testing.T) {
 	t.Parallel()
 	bt := reflect.TypeOf(true)
-	_, err := convertToType([]int{}, bt, "")
+	_, err := (&decoder{}).convertToType([]int{}, bt, "", fieldPath{})
 	if err == nil {
 		t.Error("expected error for bad bool conversion")
 	}
@@ -545,7 +580,7 @@ func TestToBool_Branches(t *testing.T) {
 
 func TestParseStringToType_Time_DefaultLayout(t *testing.T) {
 	t.Parallel()
-	v, err := parseStringToType("2024-01-01T00:00:00Z", timeType, ``)
+	v, err := (&decoder{}).parseStringToType("2024-01-01T00:00:00Z", timeType, ``)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -557,7 +592,7 @@ func TestParseStringToType_Time_DefaultLayout(t *testing.T) {
 
 func TestParseStringToType_Time_BadValue(t *testing.T) {
 	t.Parallel()
-	_, err := parseStringToType("bad", timeType, ``)
+	_, err := (&decoder{}).parseStringToType("bad", timeType, ``)
 	if err == nil {
 		t.Error("expected error for bad time string")
 	}
@@ -625,7 +660,7 @@ func TestUnmarshal_NestedStructError(t *testing.T) {
 func TestConvertToSlice_AnySlice_Error(t *testing.T) {
 	t.Parallel()
 	st := reflect.TypeOf([]int{})
-	_, err := convertToSlice([]any{"bad"}, st, ``)
+	_, err := (&decoder{}).convertToSlice([]any{"bad"}, st, ``, fieldPath{})
 	if err == nil {
 		t.Error("expected error for bad conversion in []any")
 	}
@@ -634,7 +669,7 @@ func TestConvertToSlice_AnySlice_Error(t *testing.T) {
 func TestConvertToSlice_StringSlice_Error(t *testing.T) {
 	t.Parallel()
 	st := reflect.TypeOf([]int{})
-	_, err := convertToSlice([]string{"bad"}, st, ``)
+	_, err := (&decoder{}).convertToSlice([]string{"bad"}, st, ``, fieldPath{})
 	if err == nil {
 		t.Error("expected error")
 	}
@@ -643,7 +678,7 @@ func TestConvertToSlice_StringSlice_Error(t *testing.T) {
 func TestConvertToSlice_String_Error(t *testing.T) {
 	t.Parallel()
 	st := reflect.TypeOf([]int{})
-	_, err := convertToSlice("bad", st, ``)
+	_, err := (&decoder{}).convertToSlice("bad", st, ``, fieldPath{})
 	if err == nil {
 		t.Error("expected error for invalid int parsing from string split")
 	}
@@ -652,7 +687,7 @@ func TestConvertToSlice_String_Error(t *testing.T) {
 func TestConvertToMap_ValueError(t *testing.T) {
 	t.Parallel()
 	mt := reflect.TypeOf(map[string]int{})
-	_, err := convertToMap(map[string]any{"k": "bad"}, mt)
+	_, err := (&decoder{}).convertToMap(map[string]any{"k": "bad"}, mt, fieldPath{})
 	if err == nil {
 		t.Error("expected error for bad map value conversion")
 	}
@@ -705,7 +740,7 @@ func TestUnmarshal_NilValue(t *testing.T) {
 
 func TestConvertToType_TimeDefaultLayout(t *testing.T) {
 	t.Parallel()
-	v, err := convertToType("2024-01-01T00:00:00Z", timeType, "")
+	v, err := (&decoder{}).convertToType("2024-01-01T00:00:00Z", timeType, "", fieldPath{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -714,3 +749,574 @@ func TestConvertToType_TimeDefaultLayout(t *testing.T) {
 		t.Errorf("expected 2024, got %d", ti.Year())
 	}
 }
+
+type upstreamTarget struct {
+	Host    string        `cfg:"host"`
+	Port    int           `cfg:"port" default:"80"`
+	Timeout time.Duration `cfg:"timeout" default:"1s"`
+}
+
+type collectionTarget struct {
+	Servers   []upstreamTarget                      `cfg:"servers"`
+	Pointers  []*upstreamTarget                     `cfg:"pointers"`
+	Upstreams map[string]upstreamTarget             `cfg:"upstreams"`
+	Groups    map[string][]upstreamTarget           `cfg:"groups"`
+	Matrix    [][]upstreamTarget                    `cfg:"matrix"`
+	Nested    map[string]map[string]*upstreamTarget `cfg:"nested"`
+}
+
+func TestUnmarshal_CollectionsOfStructs(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"servers": []any{
+			map[string]any{"host": "a", "port": 8080},
+			map[string]any{"host": "b"},
+		},
+		"pointers":  []any{map[string]any{"host": "p"}},
+		"upstreams": map[string]any{"api": map[string]any{"host": "api.local", "timeout": "3s"}},
+		"groups":    map[string]any{"edge": []any{map[string]any{"host": "e1"}, map[string]any{"host": "e2"}}},
+		"matrix":    []any{[]any{map[string]any{"host": "m"}}},
+		"nested":    map[string]any{"eu": map[string]any{"fra": map[string]any{"host": "fra1", "port": "443"}}},
+	})
+	var target collectionTarget
+	if err := cfg.Unmarshal("", &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(target.Servers) != 2 || target.Servers[0].Port != 8080 || target.Servers[1].Port != 80 {
+		t.Errorf("unexpected servers: %+v", target.Servers)
+	}
+	if target.Servers[1].Timeout != time.Second {
+		t.Errorf("expected default timeout in element, got %v", target.Servers[1].Timeout)
+	}
+	if len(target.Pointers) != 1 || target.Pointers[0].Host != "p" || target.Pointers[0].Port != 80 {
+		t.Errorf("unexpected pointers: %+v", target.Pointers)
+	}
+	if api := target.Upstreams["api"]; api.Host != "api.local" || api.Timeout != 3*time.Second {
+		t.Errorf("unexpected upstreams: %+v", target.Upstreams)
+	}
+	if len(target.Groups["edge"]) != 2 || target.Groups["edge"][1].Host != "e2" {
+		t.Errorf("unexpected groups: %+v", target.Groups)
+	}
+	if target.Matrix[0][0].Host != "m" {
+		t.Errorf("unexpected matrix: %+v", target.Matrix)
+	}
+	if fra := target.Nested["eu"]["fra"]; fra == nil || fra.Port != 443 {
+		t.Errorf("unexpected nested: %+v", target.Nested)
+	}
+}
+
+func TestUnmarshal_CollectionOfStructsErrors(t *testing.T) {
+	t.Parallel()
+	cases := map[string]map[string]any{
+		"element_not_map": {"servers": []any{"oops"}},
+		"field_error":     {"servers": []any{map[string]any{"port": "x"}}},
+		"map_value":       {"upstreams": map[string]any{"api": 1}},
+	}
+	for name, values := range cases {
+		var target collectionTarget
+		err := newTestConfig(values).Unmarshal("", &target)
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestConvertToStruct_NonMap(t *testing.T) {
+	t.Parallel()
+	_, err := (&decoder{}).convertToStruct("x", reflect.TypeFor[upstreamTarget](), fieldPath{})
+	if err == nil || !strings.Contains(err.Error(), "cannot convert") {
+		t.Errorf("expected conversion error, got %v", err)
+	}
+}
+
+type BaseServiceConfig struct {
+	Name     string `cfg:"name"`
+	LogLevel string `cfg:"log_level" default:"info"`
+}
+
+type baseTimeouts struct {
+	Read time.Duration `cfg:"read" default:"1s"`
+}
+
+type MetricsBlock struct {
+	Enabled bool `cfg:"enabled"`
+}
+
+type embeddedTarget struct {
+	BaseServiceConfig
+	*MetricsBlock
+	baseTimeouts
+	Port     int               `cfg:"port"`
+	Extra    BaseServiceConfig `cfg:",squash"`
+	Named    BaseServiceConfig `cfg:"named"`
+	Explicit MetricsBlock      `cfg:"metrics"`
+}
+
+func TestUnmarshal_Embedded(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"name":    "orders",
+		"port":    8080,
+		"enabled": true,
+		"read":    "3s",
+		"named":   map[string]any{"name": "inner"},
+		"metrics": map[string]any{"enabled": false},
+	})
+	var target embeddedTarget
+	if err := cfg.Unmarshal("", &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Name != "orders" || target.LogLevel != "info" {
+		t.Errorf("unexpected embedded base: %+v", target.BaseServiceConfig)
+	}
+	if target.MetricsBlock == nil || !target.Enabled {
+		t.Errorf("expected embedded pointer to be allocated and filled, got %+v", target.MetricsBlock)
+	}
+	if target.Read != 3*time.Second {
+		t.Errorf("expected unexported embedded struct fields to be set, got %v", target.Read)
+	}
+	if target.Port != 8080 || target.Extra.Name != "orders" {
+		t.Errorf("unexpected squash: %+v", target)
+	}
+	if target.Named.Name != "inner" || target.Explicit.Enabled {
+		t.Errorf("unexpected named structs: %+v %+v", target.Named, target.Explicit)
+	}
+}
+
+func TestUnmarshal_EmbeddedError(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{"enabled": "maybe"})
+	var target embeddedTarget
+	if err := cfg.Unmarshal("", &target); err == nil {
+		t.Fatal("expected error from embedded field")
+	}
+}
+
+func TestParseFieldTag(t *testing.T) {
+	t.Parallel()
+	rt := reflect.TypeFor[embeddedTarget]()
+	cases := map[string]fieldTag{
+		"BaseServiceConfig": {name: "baseserviceconfig", squash: true},
+		"Port":              {name: "port"},
+		"Extra":             {name: "extra", squash: true},
+		"Named":             {name: "named"},
+	}
+	for fieldName, want := range cases {
+		field, _ := rt.FieldByName(fieldName)
+		got, ok := parseFieldTag(field, nil)
+		if !ok || got.name != want.name || got.squash != want.squash {
+			t.Errorf("%s: got %+v, want %+v", fieldName, got, want)
+		}
+	}
+
+	squashScalar := reflect.StructField{Name: "X", Type: reflect.TypeFor[int](), Tag: `cfg:"x,squash"`}
+	if got, _ := parseFieldTag(squashScalar, nil); got.squash {
+		t.Error("expected squash to be ignored for non-struct fields")
+	}
+}
+
+type strictTarget struct {
+	Database struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+	} `cfg:"database"`
+	Servers []upstreamTarget  `cfg:"servers"`
+	Labels  map[string]string `cfg:"labels"`
+}
+
+func TestUnmarshalStrict_UnknownKeys(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"database": map[string]any{"host": "db", "hots": "x"},
+		"databse":  map[string]any{"host": "typo"},
+		"servers":  []any{map[string]any{"host": "a", "prot": 1}},
+		"labels":   map[string]any{"anything": "goes"},
+		"unrelated": map[string]any{
+			"deep": map[string]any{"key": 1},
+		},
+	})
+	var target strictTarget
+	err := cfg.UnmarshalStrict("", &target)
+
+	var unknownErr *UnknownKeysError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("expected UnknownKeysError, got %v", err)
+	}
+	want := []UnknownKey{
+		{Path: "database.hots", Suggestion: "database.host"},
+		{Path: "databse.host", Suggestion: "database.host"},
+		{Path: "servers[0].prot", Suggestion: "servers[0].port"},
+		{Path: "unrelated.deep.key"},
+	}
+	if !reflect.DeepEqual(unknownErr.Keys, want) {
+		t.Errorf("unexpected keys:\n got %+v\nwant %+v", unknownErr.Keys, want)
+	}
+	if target.Database.Host != "db" || target.Servers[0].Host != "a" {
+		t.Errorf("expected known fields to be decoded, got %+v", target)
+	}
+}
+
+func TestUnmarshalStrict_SubKey(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"app": map[string]any{"name": "svc", "log_levle": "debug"},
+	})
+	var target BaseServiceConfig
+	err := cfg.UnmarshalStrict("app", &target)
+
+	var unknownErr *UnknownKeysError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("expected UnknownKeysError, got %v", err)
+	}
+	if len(unknownErr.Keys) != 1 || unknownErr.Keys[0].Path != "app.log_levle" || unknownErr.Keys[0].Suggestion != "app.log_level" {
+		t.Errorf("unexpected keys: %+v", unknownErr.Keys)
+	}
+}
+
+func TestUnmarshalStrict_EmbeddedKeysAreKnown(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"name":    "orders",
+		"port":    8080,
+		"read":    "2s",
+		"enabled": true,
+		"named":   map[string]any{"name": "inner"},
+		"metrics": map[string]any{"enabled": false},
+	})
+	var target embeddedTarget
+	if err := cfg.UnmarshalStrict("", &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestUnmarshalStrict_DecodeErrorWins(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{"port": "abc", "extra": 1})
+	var target basicTarget
+	err := cfg.UnmarshalStrict("", &target)
+	var unknownErr *UnknownKeysError
+	if err == nil || errors.As(err, &unknownErr) {
+		t.Fatalf("expected conversion error, got %v", err)
+	}
+}
+
+func TestUnmarshal_IgnoresUnknownKeys(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{"name": "x", "nmae": "y"})
+	var target basicTarget
+	if err := cfg.Unmarshal("", &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+type aggregateTarget struct {
+	Server struct {
+		HTTP struct {
+			Port    int           `cfg:"port"`
+			Timeout time.Duration `cfg:"timeout"`
+		} `cfg:"http"`
+	} `cfg:"server"`
+	Servers  []upstreamTarget          `cfg:"servers"`
+	Limits   map[string]int            `cfg:"limits"`
+	Password Secret[int]               `cfg:"password"`
+	Token    int                       `cfg:"token"`
+	Retries  int                       `cfg:"retries" default:"many"`
+	Named    map[string]upstreamTarget `cfg:"named"`
+}
+
+func TestUnmarshal_AggregatesErrors(t *testing.T) {
+	t.Parallel()
+	cfg := &Config{
+		values: map[string]any{
+			"server": map[string]any{
+				"http": map[string]any{"port": "abc", "timeout": "soon"},
+			},
+			"servers":  []any{map[string]any{"host": "a", "port": "x"}},
+			"limits":   map[string]any{"a": 1, "b": "bad"},
+			"password": "hunter2",
+			"token":    "s3cr3t",
+			"named":    map[string]any{"main": "not a map"},
+		},
+		sensitive: newSensitiveKeys([]string{"token"}, nil),
+		sources:   sourceMap{"server.http.port": "config.yaml", "servers": "servers.json"},
+	}
+
+	var target aggregateTarget
+	err := cfg.Unmarshal("", &target)
+
+	var unmarshalErr *UnmarshalError
+	if !errors.As(err, &unmarshalErr) {
+		t.Fatalf("expected UnmarshalError, got %v", err)
+	}
+
+	got := make(map[string]FieldError)
+	for _, fe := range unmarshalErr.Errors {
+		got[fe.Path] = fe
+	}
+	want := map[string]string{
+		"server.http.port":    "Server.HTTP.Port",
+		"server.http.timeout": "Server.HTTP.Timeout",
+		"servers[0].port":     "Servers[0].Port",
+		"limits.b":            `Limits["b"]`,
+		"password":            "Password",
+		"token":               "Token",
+		"retries":             "Retries",
+		"named.main":          `Named["main"]`,
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d errors, got %d: %v", len(want), len(got), err)
+	}
+	for path, field := range want {
+		if got[path].Field != field {
+			t.Errorf("%s: expected field %q, got %+v", path, field, got[path])
+		}
+	}
+
+	port := got["server.http.port"]
+	if port.Type != "int" || port.Value != "abc" || port.Source != "config.yaml" {
+		t.Errorf("unexpected port error: %+v", port)
+	}
+	if got["servers[0].port"].Source != "servers.json" {
+		t.Errorf("expected list item source, got %+v", got["servers[0].port"])
+	}
+	if got["retries"].Source != "default" || got["retries"].Value != "many" {
+		t.Errorf("unexpected default error: %+v", got["retries"])
+	}
+	for _, secret := range []string{"hunter2", "s3cr3t"} {
+		if strings.Contains(err.Error(), secret) {
+			t.Errorf("error leaked sensitive value %q: %v", secret, err)
+		}
+	}
+	if got["token"].Value != redactedValue || got["password"].Value != redactedValue {
+		t.Errorf("expected sensitive values to be redacted: %+v %+v", got["token"], got["password"])
+	}
+}
+
+func TestUnmarshal_SecretTagRedactsErrors(t *testing.T) {
+	t.Parallel()
+	var target struct {
+		Pin  int    `cfg:"pin" secret:"true"`
+		Pins []int  `cfg:"pins" secret:"true"`
+		Code string `cfg:"code" secret:"true" validate:"regex=^[0-9]+$"`
+	}
+	cfg := newTestConfig(map[string]any{"pin": "12a34", "pins": []any{"98b76"}, "code": "ab-cd"})
+	err := cfg.Unmarshal("", &target)
+
+	var ue *UnmarshalError
+	if !errors.As(err, &ue) || len(ue.Errors) != 2 {
+		t.Fatalf("expected two field errors, got %v", err)
+	}
+	for _, fe := range ue.Errors {
+		if fe.Value != redactedValue {
+			t.Errorf("expected redacted value, got %+v", fe)
+		}
+	}
+	for _, secret := range []string{"12a34", "98b76"} {
+		if strings.Contains(err.Error(), secret) {
+			t.Errorf("error leaked %q: %v", secret, err)
+		}
+	}
+
+	cfg = newTestConfig(map[string]any{"code": "ab-cd"})
+	err = cfg.Unmarshal("", &target)
+	var ve *ValidationError
+	if !errors.As(err, &ve) || len(ve.Details) != 1 || ve.Details[0].Value != redactedValue || strings.Contains(err.Error(), "ab-cd") {
+		t.Errorf("expected redacted violation, got %v", err)
+	}
+}
+
+func TestUnmarshal_ErrorUnwrapsCause(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{"url": 1})
+	var target decoderTarget
+	err := cfg.Unmarshal("", &target)
+
+	var fieldErr FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "url" || fieldErr.Type != "url.URL" {
+		t.Fatalf("expected FieldError for url, got %v", err)
+	}
+}
+
+func TestUnmarshal_SubKeyErrorPath(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"app": map[string]any{"db": map[string]any{"host": "h"}},
+	})
+	var target struct {
+		DB struct {
+			Host int `cfg:"host"`
+		} `cfg:"db"`
+	}
+	err := cfg.Unmarshal("app", &target)
+
+	var unmarshalErr *UnmarshalError
+	if !errors.As(err, &unmarshalErr) || unmarshalErr.Errors[0].Path != "app.db.host" || unmarshalErr.Errors[0].Field != "DB.Host" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+type namingTarget struct {
+	MaxIdleConns int `default:"1"`
+	HTTPServer   struct {
+		ReadTimeout time.Duration
+	}
+	Tagged string `cfg:"custom_name"`
+}
+
+func TestUnmarshal_KeyNaming(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		naming NameMapper
+		values map[string]any
+	}{
+		"snake": {SnakeCase, map[string]any{"max_idle_conns": 10, "http_server": map[string]any{"read_timeout": "5s"}}},
+		"kebab": {KebabCase, map[string]any{"max-idle-conns": 10, "http-server": map[string]any{"read-timeout": "5s"}}},
+		"camel": {CamelCase, map[string]any{"maxIdleConns": 10, "httpServer": map[string]any{"readTimeout": "5s"}}},
+		"exact": {ExactCase, map[string]any{"MaxIdleConns": 10, "HTTPServer": map[string]any{"ReadTimeout": "5s"}}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			tc.values["custom_name"] = "tagged"
+			cfg, err := New(WithLoader(&staticLoader{data: tc.values}), WithKeyNaming(tc.naming))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var target namingTarget
+			if err := cfg.UnmarshalStrict("", &target); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if target.MaxIdleConns != 10 || target.HTTPServer.ReadTimeout != 5*time.Second || target.Tagged != "tagged" {
+				t.Errorf("unexpected target: %+v", target)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_CaseInsensitiveKeys(t *testing.T) {
+	t.Parallel()
+	values := map[string]any{"Max_Idle_Conns": 3, "HTTP_SERVER": map[string]any{"Read_Timeout": "2s"}}
+
+	cfg, err := New(WithLoader(&staticLoader{data: values}), WithKeyNaming(SnakeCase), WithCaseInsensitiveKeys())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var target namingTarget
+	if err := cfg.UnmarshalStrict("", &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.MaxIdleConns != 3 || target.HTTPServer.ReadTimeout != 2*time.Second {
+		t.Errorf("unexpected target: %+v", target)
+	}
+
+	sub, _ := cfg.GetSub("HTTP_SERVER")
+	var inner struct{ ReadTimeout time.Duration }
+	if err := sub.Unmarshal("", &inner); err != nil || inner.ReadTimeout != 2*time.Second {
+		t.Errorf("expected sub config to keep naming options, got %+v, %v", inner, err)
+	}
+}
+
+func TestUnmarshal_DefaultNamingIsLowercase(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{"maxidleconns": 7, "max_idle_conns": 8})
+	var target namingTarget
+	if err := cfg.Unmarshal("", &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.MaxIdleConns != 7 {
+		t.Errorf("expected 7, got %d", target.MaxIdleConns)
+	}
+}
+
+type taggedValidationTarget struct {
+	Server struct {
+		Host string `cfg:"host,required"`
+		Port int    `cfg:"port,required" validate:"min=1,max=65535"`
+	} `cfg:"server,required"`
+	Log struct {
+		Level string `cfg:"level" validate:"oneof=debug info warn error" default:"info"`
+	} `cfg:"log"`
+	Name     string      `cfg:"name" validate:"regex=^[a-z][a-z0-9-]{2,30}$"`
+	Password Secret[int] `cfg:"password" validate:"min=1000"`
+	Cache    struct {
+		TTL time.Duration `cfg:"ttl,required"`
+	} `cfg:"cache,required"`
+}
+
+func TestUnmarshal_ValidationTags(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"server":   map[string]any{"port": 70000},
+		"log":      map[string]any{"level": "trace"},
+		"name":     "Bad_Name",
+		"password": 42,
+	})
+	var target taggedValidationTarget
+	err := cfg.Unmarshal("", &target)
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	want := []string{
+		`"server.host": required key is missing`,
+		`"server.port": value 70000 is out of range [1, 65535]`,
+		`"log.level": value "trace" is not one of [debug info warn error]`,
+		`"name": value "Bad_Name" does not match pattern`,
+		`"password": sensitive value failed validation`,
+		`"cache": required key is missing`,
+	}
+	if len(ve.Violations) != len(want) {
+		t.Fatalf("expected %d violations, got %v", len(want), ve.Violations)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(ve.Violations[i], w) {
+			t.Errorf("violation %d: expected prefix %q, got %q", i, w, ve.Violations[i])
+		}
+	}
+	if strings.Contains(err.Error(), "42") {
+		t.Errorf("validation error leaked secret: %v", err)
+	}
+	codes := []ViolationCode{CodeRequired, CodeRange, CodeEnum, CodePattern, CodeRange, CodeRequired}
+	for i, code := range codes {
+		if ve.Details[i].Code != code {
+			t.Errorf("detail %d: expected code %s, got %s", i, code, ve.Details[i].Code)
+		}
+	}
+	if ve.Details[1].Value != 70000 || ve.Details[4].Value != redactedValue {
+		t.Errorf("unexpected detail values: %v, %v", ve.Details[1].Value, ve.Details[4].Value)
+	}
+	if target.Server.Port != 70000 {
+		t.Error("expected values to be decoded despite violations")
+	}
+}
+
+func TestUnmarshal_ValidationTagsPass(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"server":   map[string]any{"host": "localhost", "port": "8080"},
+		"log":      map[string]any{},
+		"name":     "orders-api",
+		"password": 1234,
+		"cache":    map[string]any{"ttl": "1m"},
+	})
+	var target taggedValidationTarget
+	if err := cfg.Unmarshal("", &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Log.Level != "info" {
+		t.Errorf("expected default level, got %q", target.Log.Level)
+	}
+}
+
+func TestUnmarshal_ConversionErrorsSkipValidation(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{"server": map[string]any{"host": "h", "port": "abc"}, "cache": map[string]any{"ttl": "1m"}})
+	var target taggedValidationTarget
+	err := cfg.Unmarshal("", &target)
+
+	var unmarshalErr *UnmarshalError
+	if !errors.As(err, &unmarshalErr) {
+		t.Fatalf("expected UnmarshalError, got %v", err)
+	}
+}
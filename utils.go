@@ -1,12 +1,14 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func deepCopyMap(src map[string]any) map[string]any {
@@ -39,9 +41,12 @@ func deepCopyValue(v any) any {
 func mergeMaps(dst, src map[string]any) {
 	for k, v := range src {
 		if vMap, ok := v.(map[string]any); ok {
-			if dstV, exists := dst[k]; exists {
-				if dstMap, ok := dstV.(map[string]any); ok {
-					mergeMaps(dstMap, vMap)
+			switch dstV := dst[k].(type) {
+			case map[string]any:
+				mergeMaps(dstV, vMap)
+				continue
+			case []any:
+				if mergeIndexed(dstV, vMap) {
 					continue
 				}
 			}
@@ -50,6 +55,44 @@ func mergeMaps(dst, src map[string]any) {
 	}
 }
 
+func mergeIndexed(dst []any, src map[string]any) bool {
+	for k := range src {
+		if _, ok := listIndex(k, len(dst)); !ok {
+			return false
+		}
+	}
+	for k, v := range src {
+		i, _ := listIndex(k, len(dst))
+		item := map[string]any{k: dst[i]}
+		mergeMaps(item, map[string]any{k: v})
+		dst[i] = item[k]
+	}
+	return true
+}
+
+func indexedItems(m map[string]any) ([]any, bool) {
+	if len(m) == 0 {
+		return nil, false
+	}
+	items := make([]any, len(m))
+	for k, item := range m {
+		i, ok := listIndex(k, len(m))
+		if !ok {
+			return nil, false
+		}
+		items[i] = item
+	}
+	return items, true
+}
+
+func listIndex(k string, n int) (int, bool) {
+	i, err := strconv.Atoi(k)
+	if err != nil || i < 0 || i >= n || strconv.Itoa(i) != k {
+		return 0, false
+	}
+	return i, true
+}
+
 func normalizeMap(m map[string]any) map[string]any {
 	out := make(map[string]any, len(m))
 	for k, v := range m {
@@ -74,6 +117,8 @@ func normalizeValue(v any) any {
 			out[i] = normalizeValue(item)
 		}
 		return out
+	case json.Number:
+		return jsonNumber(val)
 	default:
 		return v
 	}
@@ -104,6 +149,61 @@ func setNested(m map[string]any, key string, value any) {
 	}
 }
 
+func lookupPath(values map[string]any, path string) (any, bool) {
+	keys := strings.Split(path, ".")
+	var current any = values
+
+	for _, k := range keys {
+		if current == nil {
+			return nil, false
+		}
+		switch cur := current.(type) {
+		case map[string]any:
+			next, exists := cur[k]
+			if !exists {
+				return nil, false
+			}
+			current = next
+		case map[any]any:
+			next, exists := cur[k]
+			if !exists {
+				return nil, false
+			}
+			current = next
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func deletePath(m map[string]any, path string) {
+	head, rest, nested := strings.Cut(path, ".")
+	if !nested {
+		delete(m, head)
+		return
+	}
+	child, ok := m[head].(map[string]any)
+	if !ok {
+		return
+	}
+	deletePath(child, rest)
+	if len(child) == 0 {
+		delete(m, head)
+	}
+}
+
+func flattenValue(prefix string, v any, out map[string]any) {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) == 0 {
+		out[prefix] = v
+		return
+	}
+	for k, item := range m {
+		flattenValue(joinKey(prefix, k), item, out)
+	}
+}
+
 func expandDotKeys(flat map[string]any) map[string]any {
 	out := make(map[string]any)
 	for k, v := range flat {
@@ -207,6 +307,16 @@ func toInt(v any) (int, bool) {
 	return 0, false
 }
 
+func toString(v any) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
+
 func toFloat64(v any) (float64, bool) {
 	switch val := v.(type) {
 	case float64:
@@ -224,3 +334,80 @@ func toFloat64(v any) (float64, bool) {
 	}
 	return 0, false
 }
+
+func toDuration(v any) (time.Duration, bool) {
+	switch val := v.(type) {
+	case time.Duration:
+		return val, true
+	case string:
+		if d, err := time.ParseDuration(val); err == nil {
+			return d, true
+		}
+	case int:
+		return time.Duration(val) * time.Millisecond, true
+	case int64:
+		return time.Duration(val) * time.Millisecond, true
+	case float64:
+		return time.Duration(val * float64(time.Millisecond)), true
+	}
+	return 0, false
+}
+
+func looseEqual(want, got any) bool {
+	if b, ok := want.(bool); ok {
+		v, ok := toBool(got)
+		return ok && v == b
+	}
+	wf, wok := toFloat64(want)
+	gf, gok := toFloat64(got)
+	if _, isString := want.(string); !isString && wok && gok {
+		return wf == gf
+	}
+	return toString(want) == toString(got)
+}
+
+func joinKey(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func indexKey(parent string, i int) string {
+	return fmt.Sprintf("%s[%d]", parent, i)
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func nearestKey(key string, candidates []string) string {
+	best, bestDist := "", -1
+	for _, c := range candidates {
+		d := levenshtein(strings.ToLower(key), strings.ToLower(c))
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	if bestDist < 0 || bestDist > max(2, len(key)/3) || bestDist >= len(key) {
+		return ""
+	}
+	return best
+}
@@ -276,3 +276,59 @@ func TestDeepCopyValue_AllTypes(t *testing.T) {
 		t.Error("scalar copy failed")
 	}
 }
+
+func TestLevenshtein(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"host", "host", 0},
+		{"hots", "host", 2},
+		{"databse", "database", 1},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+	}
+	for _, tc := range cases {
+		if got := levenshtein(tc.a, tc.b); got != tc.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestNearestKey(t *testing.T) {
+	t.Parallel()
+	candidates := []string{"host", "port", "timeout"}
+	if got := nearestKey("prot", candidates); got != "port" {
+		t.Errorf("expected port, got %q", got)
+	}
+	if got := nearestKey("TimeOut", candidates); got != "timeout" {
+		t.Errorf("expected timeout, got %q", got)
+	}
+	if got := nearestKey("completely_different", candidates); got != "" {
+		t.Errorf("expected no suggestion, got %q", got)
+	}
+	if got := nearestKey("x", candidates); got != "" {
+		t.Errorf("expected no suggestion for short key, got %q", got)
+	}
+	if got := nearestKey("host", nil); got != "" {
+		t.Errorf("expected no suggestion without candidates, got %q", got)
+	}
+}
+
+func TestFlattenValue(t *testing.T) {
+	t.Parallel()
+	out := make(map[string]any)
+	flattenValue("root", map[string]any{
+		"a":     1,
+		"b":     map[string]any{"c": "x"},
+		"empty": map[string]any{},
+	}, out)
+	if len(out) != 3 || out["root.a"] != 1 || out["root.b.c"] != "x" {
+		t.Errorf("unexpected flatten result: %v", out)
+	}
+	if _, ok := out["root.empty"]; !ok {
+		t.Error("expected empty map to be kept as a leaf")
+	}
+}
@@ -1,16 +1,25 @@
 package config
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"math"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Rule func(c *Config) error
 
+var valueCodes = []ViolationCode{CodeRange, CodeEnum, CodePattern, CodeType, CodeFormat, CodeLength}
+
 func Required(key string) Rule {
 	return func(c *Config) error {
 		if !c.Has(key) {
-			return fmt.Errorf("%q: required key is missing", key)
+			return missingKeyError(key)
 		}
 		return nil
 	}
@@ -21,78 +30,396 @@ func InRange(key string, min, max float64) Rule {
 		if !c.Has(key) {
 			return nil
 		}
+		return checkRange(key, c.Get(key), min, max)
+	}
+}
 
-		v, ok := toFloat64(c.Get(key))
-		if !ok {
-			return fmt.Errorf("%q: value is not a number", key)
+func OneOf(key string, allowed ...string) Rule {
+	return func(c *Config) error {
+		if !c.Has(key) {
+			return nil
 		}
+		return checkOneOf(key, c.Get(key), allowed)
+	}
+}
 
-		if v < min || v > max {
-			return fmt.Errorf("%q: value %v is out of range [%v, %v]", key, v, min, max)
+func MatchRegex(key string, pattern string) Rule {
+	return func(c *Config) error {
+		if !c.Has(key) {
+			return nil
 		}
+		return checkRegex(key, c.Get(key), pattern)
+	}
+}
 
+func Custom(key string, fn func(v any) error) Rule {
+	return func(c *Config) error {
+		v := c.Get(key)
+		if err := fn(v); err != nil {
+			return Violation{Key: key, Code: CodeCustom, Value: v, Message: err.Error(), err: err}
+		}
 		return nil
 	}
 }
 
-func OneOf(key string, allowed ...string) Rule {
+type Condition func(c *Config) bool
+
+func IsSet(key string) Condition {
+	return func(c *Config) bool {
+		return c.Has(key)
+	}
+}
+
+func Equals(key string, value any) Condition {
+	return func(c *Config) bool {
+		v, ok := c.find(key)
+		return ok && looseEqual(value, v)
+	}
+}
+
+func RequiredIf(key, condKey string, condValue any) Rule {
 	return func(c *Config) error {
-		if !c.Has(key) {
+		if c.Has(key) || !Equals(condKey, condValue)(c) {
 			return nil
 		}
+		return Violation{
+			Key:     key,
+			Code:    CodeRequired,
+			Message: fmt.Sprintf("required key is missing when %q is %v", condKey, condValue),
+		}
+	}
+}
 
-		v := c.GetString(key)
-		for _, a := range allowed {
-			if v == a {
-				return nil
-			}
+func MutuallyExclusive(keys ...string) Rule {
+	return func(c *Config) error {
+		present := slices.DeleteFunc(slices.Clone(keys), func(k string) bool { return !c.Has(k) })
+		if len(present) < 2 {
+			return nil
+		}
+		return Violation{
+			Key:     present[1],
+			Code:    CodeConflict,
+			Value:   c.Get(present[1]),
+			Message: fmt.Sprintf("keys %q are mutually exclusive", present),
 		}
+	}
+}
 
-		return fmt.Errorf("%q: value %q is not one of %v", key, v, allowed)
+func AtLeastOneOf(keys ...string) Rule {
+	return func(c *Config) error {
+		if slices.ContainsFunc(keys, c.Has) {
+			return nil
+		}
+		return Violation{
+			Key:     getFirst(keys),
+			Code:    CodeRequired,
+			Message: fmt.Sprintf("at least one of %q is required", keys),
+		}
 	}
 }
 
-func MatchRegex(key string, pattern string) Rule {
+func LessThan(key, otherKey string) Rule {
 	return func(c *Config) error {
-		if !c.Has(key) {
+		a, aok := c.find(key)
+		b, bok := c.find(otherKey)
+		if !aok || !bok {
 			return nil
 		}
 
-		v := c.GetString(key)
-		matched, err := regexp.MatchString(pattern, v)
-		if err != nil {
-			return fmt.Errorf("%q: invalid regex %q: %w", key, pattern, err)
+		cmp, ok := compareValues(a, b)
+		if !ok {
+			return Violation{Key: key, Code: CodeType, Value: a, Message: fmt.Sprintf("value cannot be compared with %q", otherKey)}
 		}
-		if !matched {
-			return fmt.Errorf("%q: value %q does not match pattern %q", key, v, pattern)
+		if cmp >= 0 {
+			return Violation{Key: key, Code: CodeRange, Value: a, Message: fmt.Sprintf("value %v must be less than %q (%v)", a, otherKey, b)}
 		}
+		return nil
+	}
+}
 
+func Warn(rules ...Rule) Rule {
+	return func(c *Config) error {
+		errs := runRules(c, rules)
+		for i, err := range errs {
+			v := asViolation(err)
+			v.Severity = SeverityWarning
+			errs[i] = v
+		}
+		if len(errs) > 0 {
+			return newValidationError(errs)
+		}
 		return nil
 	}
 }
 
-func Custom(key string, fn func(v any) error) Rule {
+func Deprecated(key, replacement, message string) Rule {
 	return func(c *Config) error {
-		v := c.Get(key)
-		if err := fn(v); err != nil {
-			return fmt.Errorf("%q: %w", key, err)
+		if !c.Has(key) {
+			return nil
+		}
+		msg := "key is deprecated"
+		if replacement != "" {
+			msg += fmt.Sprintf(", use %q instead", replacement)
+		}
+		if message != "" {
+			msg += ": " + message
+		}
+		return Violation{Key: key, Code: CodeDeprecated, Value: c.Get(key), Message: msg, Severity: SeverityWarning}
+	}
+}
+
+func When(cond Condition, rules ...Rule) Rule {
+	return func(c *Config) error {
+		if !cond(c) {
+			return nil
+		}
+		return All(rules...)(c)
+	}
+}
+
+func All(rules ...Rule) Rule {
+	return func(c *Config) error {
+		if errs := runRules(c, rules); len(errs) > 0 {
+			return newValidationError(errs)
+		}
+		return nil
+	}
+}
+
+func Any(rules ...Rule) Rule {
+	return func(c *Config) error {
+		var errs []error
+		for _, rule := range rules {
+			failed := runRules(c, []Rule{rule})
+			if len(failed) == 0 {
+				return nil
+			}
+			errs = append(errs, failed...)
+		}
+		if len(errs) == 0 {
+			return nil
+		}
+
+		messages := make([]string, len(errs))
+		for i, err := range errs {
+			messages[i] = err.Error()
+		}
+		return Violation{
+			Key:     asViolation(errs[0]).Key,
+			Code:    CodeCustom,
+			Message: "no alternative passed: " + strings.Join(messages, "; "),
+			err:     errors.Join(errs...),
+		}
+	}
+}
+
+func Each(key string, rules ...Rule) Rule {
+	return func(c *Config) error {
+		v, ok := c.find(key)
+		if !ok {
+			return nil
+		}
+		items, ok := v.([]any)
+		if !ok {
+			return Violation{Key: key, Code: CodeType, Value: v, Message: fmt.Sprintf("expected a list, got %T", v)}
+		}
+
+		var errs []error
+		for i, item := range items {
+			at := indexKey(key, i)
+			elem, ok := item.(map[string]any)
+			if !ok {
+				elem = map[string]any{"": item}
+			}
+			for _, err := range runRules(&Config{values: elem}, rules) {
+				errs = append(errs, rebaseViolation(err, at))
+			}
+		}
+		if len(errs) > 0 {
+			return newValidationError(errs)
 		}
 		return nil
 	}
 }
 
 func (c *Config) Validate(rules ...Rule) error {
-	var violations []string
+	warnings, err := c.ValidateWithWarnings(rules...)
+	for _, v := range warnings {
+		c.reportWarning(v)
+	}
+	return err
+}
+
+func (c *Config) ValidateWithWarnings(rules ...Rule) ([]Violation, error) {
+	violations := runRules(c, rules)
+
+	var errs []error
+	var warnings []Violation
+	for _, err := range violations {
+		v, ok := err.(Violation)
+		if !ok {
+			errs = append(errs, err)
+			continue
+		}
+		v = c.describeViolation(v)
+		if v.Severity == SeverityWarning {
+			warnings = append(warnings, v)
+			continue
+		}
+		errs = append(errs, v)
+	}
+
+	if len(errs) > 0 {
+		return warnings, newValidationError(errs)
+	}
+
+	return warnings, nil
+}
 
+func (c *Config) reportWarning(v Violation) {
+	if c.warn != nil {
+		c.warn(v)
+	}
+}
+
+func runRules(c *Config, rules []Rule) []error {
+	var errs []error
 	for _, rule := range rules {
-		if err := rule(c); err != nil {
-			violations = append(violations, err.Error())
+		err := rule(c)
+		var nested *ValidationError
+		switch {
+		case errors.As(err, &nested):
+			errs = append(errs, nested.errs...)
+		case err != nil:
+			errs = append(errs, err)
 		}
 	}
+	return errs
+}
+
+func rebaseViolation(err error, parent string) error {
+	v, ok := err.(Violation)
+	if !ok {
+		return Violation{Key: parent, Code: CodeCustom, Message: err.Error(), err: err}
+	}
+	if v.Key == "" {
+		v.Key = parent
+	} else {
+		v.Key = joinKey(parent, v.Key)
+	}
+	return v
+}
 
-	if len(violations) > 0 {
-		return &ValidationError{Violations: violations}
+func compareValues(a, b any) (int, bool) {
+	af, aok := toFloat64(a)
+	bf, bok := toFloat64(b)
+	if aok && bok {
+		return cmp.Compare(af, bf), true
+	}
+
+	ad, aerr := time.ParseDuration(toString(a))
+	bd, berr := time.ParseDuration(toString(b))
+	if aerr == nil && berr == nil {
+		return cmp.Compare(ad, bd), true
+	}
+
+	return 0, false
+}
+
+func (c *Config) describeViolation(v Violation) Violation {
+	if v.Source == "" {
+		v.Source = c.Source(v.Key)
+	}
+	if v.Value != nil && c.IsSensitive(v.Key) {
+		v.Value = redactedValue
+		if slices.Contains(valueCodes, v.Code) {
+			v.Message = "sensitive value failed validation"
+		}
+	}
+	return v
+}
+
+func missingKeyError(key string) error {
+	return Violation{Key: key, Code: CodeRequired, Message: "required key is missing"}
+}
+
+func checkRange(key string, val any, min, max float64) error {
+	v, ok := toFloat64(val)
+	if !ok {
+		return Violation{Key: key, Code: CodeRange, Value: val, Message: "value is not a number"}
+	}
+
+	if v < min || v > max {
+		return Violation{Key: key, Code: CodeRange, Value: val, Message: fmt.Sprintf("value %v is out of range [%v, %v]", v, min, max)}
 	}
 
 	return nil
 }
+
+func checkOneOf(key string, val any, allowed []string) error {
+	v := toString(val)
+	for _, a := range allowed {
+		if v == a {
+			return nil
+		}
+	}
+
+	return Violation{Key: key, Code: CodeEnum, Value: val, Message: fmt.Sprintf("value %q is not one of %v", v, allowed)}
+}
+
+func checkRegex(key string, val any, pattern string) error {
+	v := toString(val)
+	matched, err := regexp.MatchString(pattern, v)
+	if err != nil {
+		return Violation{Key: key, Code: CodePattern, Value: val, Message: fmt.Sprintf("invalid regex %q: %v", pattern, err), err: err}
+	}
+	if !matched {
+		return Violation{Key: key, Code: CodePattern, Value: val, Message: fmt.Sprintf("value %q does not match pattern %q", v, pattern)}
+	}
+
+	return nil
+}
+
+func checkTag(key string, val any, spec string) []error {
+	var errs []error
+
+	lo, hi := math.Inf(-1), math.Inf(1)
+	hasRange := false
+
+	for spec != "" {
+		var part string
+		if strings.HasPrefix(spec, "regex=") {
+			part, spec = spec, ""
+		} else {
+			part, spec, _ = strings.Cut(spec, ",")
+		}
+
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "min", "max":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				errs = append(errs, Violation{Key: key, Code: CodeCustom, Message: fmt.Sprintf("invalid validate rule %q", part)})
+				continue
+			}
+			if name == "min" {
+				lo = n
+			} else {
+				hi = n
+			}
+			hasRange = true
+		case "oneof":
+			errs = append(errs, checkOneOf(key, val, strings.Fields(arg)))
+		case "regex":
+			errs = append(errs, checkRegex(key, val, arg))
+		default:
+			errs = append(errs, Violation{Key: key, Code: CodeCustom, Message: fmt.Sprintf("unknown validate rule %q", name)})
+		}
+	}
+
+	if hasRange {
+		errs = append(errs, checkRange(key, val, lo, hi))
+	}
+
+	return slices.DeleteFunc(errs, func(err error) bool { return err == nil })
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -181,3 +182,315 @@ func TestValidate_MixedRules(t *testing.T) {
 		t.Errorf("expected 'out of range' in error, got %s", err.Error())
 	}
 }
+
+func TestValidate_Details(t *testing.T) {
+	t.Parallel()
+	errBad := errors.New("bad name")
+	cfg := newTestConfig(map[string]any{
+		"port":     99999,
+		"level":    "trace",
+		"name":     "x",
+		"password": "hunter2",
+	})
+	cfg.sensitive = newSensitiveKeys([]string{"password"}, nil)
+	cfg.sources = sourceMap{"port": "config.yaml"}
+
+	err := cfg.Validate(
+		Required("host"),
+		InRange("port", 1, 65535),
+		OneOf("level", "info", "warn"),
+		MatchRegex("password", "^.{8,}$"),
+		Custom("name", func(any) error { return errBad }),
+	)
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	want := []Violation{
+		{Key: "host", Code: CodeRequired, Message: "required key is missing"},
+		{Key: "port", Code: CodeRange, Value: 99999, Message: "value 99999 is out of range [1, 65535]", Source: "config.yaml"},
+		{Key: "level", Code: CodeEnum, Value: "trace", Message: `value "trace" is not one of [info warn]`},
+		{Key: "password", Code: CodePattern, Value: redactedValue, Message: "sensitive value failed validation"},
+		{Key: "name", Code: CodeCustom, Value: "x", Message: "bad name", err: errBad},
+	}
+	if len(ve.Details) != len(want) {
+		t.Fatalf("expected %d details, got %v", len(want), ve.Details)
+	}
+	for i, w := range want {
+		if ve.Details[i] != w {
+			t.Errorf("detail %d: expected %+v, got %+v", i, w, ve.Details[i])
+		}
+		if ve.Violations[i] != w.Error() {
+			t.Errorf("violation %d: expected %q, got %q", i, w.Error(), ve.Violations[i])
+		}
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Error("expected sensitive value to be redacted")
+	}
+	if !errors.Is(err, errBad) {
+		t.Error("expected errors.Is to reach the custom error")
+	}
+}
+
+func TestCheckTag(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name string
+		val  any
+		spec string
+		want []string
+	}{
+		{"range ok", 8080, "min=1,max=65535", nil},
+		{"below min", 0, "min=1,max=65535", []string{"out of range [1, 65535]"}},
+		{"only max", "70000", "max=65535", []string{"out of range [-Inf, 65535]"}},
+		{"not a number", "abc", "min=1", []string{"not a number"}},
+		{"oneof ok", "warn", "oneof=debug info warn", nil},
+		{"oneof fail", "trace", "oneof=debug info warn", []string{"is not one of [debug info warn]"}},
+		{"regex with comma", "aaa", "min=0,regex=^a{1,3}$", []string{"not a number"}},
+		{"regex fail", "bbbb", "regex=^a{1,3}$", []string{"does not match pattern"}},
+		{"unknown", 1, "foo=bar", []string{`unknown validate rule "foo"`}},
+		{"bad number", 1, "min=x", []string{`invalid validate rule "min=x"`}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			errs := checkTag("key", tc.val, tc.spec)
+			if len(errs) != len(tc.want) {
+				t.Fatalf("expected %d errors, got %v", len(tc.want), errs)
+			}
+			for i, want := range tc.want {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("expected %q in %q", want, errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRequiredIf(t *testing.T) {
+	t.Parallel()
+	enabled := newTestConfig(map[string]any{"tls": map[string]any{"enabled": "true"}})
+	if err := RequiredIf("tls.cert", "tls.enabled", true)(enabled); err == nil || !strings.Contains(err.Error(), `when "tls.enabled" is true`) {
+		t.Errorf("expected missing cert, got %v", err)
+	}
+
+	disabled := newTestConfig(map[string]any{"tls": map[string]any{"enabled": false}})
+	if err := RequiredIf("tls.cert", "tls.enabled", true)(disabled); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMutuallyExclusive(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{"password": "x", "key_file": "y"})
+	err := MutuallyExclusive("password", "token", "key_file")(cfg)
+	v, ok := err.(Violation)
+	if !ok || v.Code != CodeConflict || v.Key != "key_file" {
+		t.Errorf("unexpected result: %#v", err)
+	}
+	if err = MutuallyExclusive("password", "token")(cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAtLeastOneOf(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{"token": "x"})
+	if err := AtLeastOneOf("password", "token")(cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := AtLeastOneOf("password", "key_file")(cfg); err == nil || !strings.Contains(err.Error(), "at least one of") {
+		t.Errorf("expected violation, got %v", err)
+	}
+}
+
+func TestLessThan(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"pool":    map[string]any{"min": 10, "max": "5"},
+		"timeout": map[string]any{"read": "30s", "write": "1m"},
+		"name":    "x",
+	})
+	if err := LessThan("pool.min", "pool.max")(cfg); err == nil || !strings.Contains(err.Error(), `must be less than "pool.max"`) {
+		t.Errorf("expected violation, got %v", err)
+	}
+	if err := LessThan("timeout.read", "timeout.write")(cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := LessThan("name", "pool.max")(cfg); err == nil || !strings.Contains(err.Error(), "cannot be compared") {
+		t.Errorf("expected type violation, got %v", err)
+	}
+	if err := LessThan("pool.min", "missing")(cfg); err != nil {
+		t.Errorf("expected missing key to be skipped, got %v", err)
+	}
+}
+
+func TestWhenAllAny(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{"mode": "cluster", "port": 0})
+	err := cfg.Validate(
+		When(Equals("mode", "cluster"), Required("cluster.peers"), InRange("port", 1, 65535)),
+		When(IsSet("standalone"), Required("never")),
+		Any(Required("password"), Required("token")),
+		Any(Required("mode"), Required("token")),
+	)
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	want := []string{
+		`"cluster.peers": required key is missing`,
+		`"port": value 0 is out of range [1, 65535]`,
+		`"password": no alternative passed: "password": required key is missing; "token": required key is missing`,
+	}
+	if !slices.Equal(ve.Violations, want) {
+		t.Errorf("unexpected violations:\n%s", strings.Join(ve.Violations, "\n"))
+	}
+}
+
+func TestEach(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{
+		"servers": []any{
+			map[string]any{"host": "a", "port": 80},
+			map[string]any{"port": 70000},
+		},
+		"ports": []any{80, 0},
+		"name":  "x",
+	})
+	cfg.sources = sourceMap{"servers": "config.yaml"}
+
+	err := cfg.Validate(
+		Each("servers", Required("host"), InRange("port", 1, 65535)),
+		Each("ports", InRange("", 1, 65535)),
+		Each("name", Required("host")),
+		Each("missing", Required("host")),
+	)
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	want := []string{
+		`"servers[1].host": required key is missing`,
+		`"servers[1].port": value 70000 is out of range [1, 65535]`,
+		`"ports[1]": value 0 is out of range [1, 65535]`,
+		`"name": expected a list, got string`,
+	}
+	if !slices.Equal(ve.Violations, want) {
+		t.Errorf("unexpected violations:\n%s", strings.Join(ve.Violations, "\n"))
+	}
+	if ve.Details[1].Source != "config.yaml" {
+		t.Errorf("expected source of the list, got %q", ve.Details[1].Source)
+	}
+}
+
+func TestWarnAndDeprecated(t *testing.T) {
+	t.Parallel()
+	var warnings []Violation
+	cfg, err := New(
+		WithLoader(&staticLoader{data: map[string]any{
+			"db":   map[string]any{"pass": "hunter2", "port": 70000},
+			"name": "x",
+		}}),
+		WithSensitiveKeys("db.pass"),
+		WithWarningHandler(func(v Violation) { warnings = append(warnings, v) }),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = cfg.Validate(
+		Deprecated("db.pass", "db.password", "will be removed in v3"),
+		Deprecated("db.user", "db.username", ""),
+		Warn(InRange("db.port", 1, 65535), MinLen("name", 3)),
+		Required("db.host"),
+	)
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) || !slices.Equal(ve.Violations, []string{`"db.host": required key is missing`}) {
+		t.Fatalf("expected only the error-level violation, got %v", err)
+	}
+
+	want := []string{
+		`"db.pass": key is deprecated, use "db.password" instead: will be removed in v3`,
+		`"db.port": value 70000 is out of range [1, 65535]`,
+		`"name": length 1 is less than 3`,
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("expected %d warnings, got %v", len(want), warnings)
+	}
+	for i, w := range want {
+		if warnings[i].Error() != w || warnings[i].Severity != SeverityWarning {
+			t.Errorf("warning %d: expected %q, got %q (%s)", i, w, warnings[i].Error(), warnings[i].Severity)
+		}
+	}
+	if warnings[0].Code != CodeDeprecated || warnings[0].Value != redactedValue {
+		t.Errorf("unexpected deprecation: %+v", warnings[0])
+	}
+
+	if err = cfg.Validate(Warn(Required("missing"))); err != nil {
+		t.Errorf("expected warnings alone not to fail, got %v", err)
+	}
+}
+
+func TestValidateWithWarnings(t *testing.T) {
+	t.Parallel()
+	cfg := FromMap(map[string]any{"old": "x", "port": 0})
+
+	warnings, err := cfg.ValidateWithWarnings(
+		Deprecated("old", "new", ""),
+		Warn(Required("missing")),
+		InRange("port", 1, 65535),
+	)
+	var ve *ValidationError
+	if !errors.As(err, &ve) || len(ve.Violations) != 1 || !strings.Contains(ve.Violations[0], "port") {
+		t.Errorf("expected port error, got %v", err)
+	}
+	if len(warnings) != 2 || warnings[0].Code != CodeDeprecated || warnings[1].Key != "missing" {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	for _, w := range warnings {
+		if w.Severity != SeverityWarning {
+			t.Errorf("expected warning severity, got %+v", w)
+		}
+	}
+
+	warnings, err = cfg.ValidateWithWarnings(Required("old"))
+	if err != nil || warnings != nil {
+		t.Errorf("expected no warnings or errors, got %v, %v", warnings, err)
+	}
+}
+
+type warnRecordingLogger struct {
+	recordingLogger
+	warnings []string
+}
+
+func (l *warnRecordingLogger) Warn(msg string, args ...any) {
+	l.warnings = append(l.warnings, fmt.Sprint(append([]any{msg}, args...)...))
+}
+
+func TestWarn_Logger(t *testing.T) {
+	t.Parallel()
+	warner := &warnRecordingLogger{}
+	cfg, err := New(WithLogger(warner), WithLoader(&staticLoader{data: map[string]any{"old": 1}}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = cfg.Validate(Deprecated("old", "new", "")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warner.warnings) != 1 || !strings.Contains(warner.warnings[0], "deprecated") {
+		t.Errorf("expected warning through Warn, got %v", warner.warnings)
+	}
+
+	debug := &recordingLogger{}
+	cfg, _ = New(WithLogger(debug), WithLoader(&staticLoader{data: map[string]any{"old": 1}}))
+	_ = cfg.Validate(Deprecated("old", "new", ""))
+	if !strings.Contains(strings.Join(debug.lines, "\n"), "validation warning") {
+		t.Errorf("expected warning through Debug, got %v", debug.lines)
+	}
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+func IsURL(key string, schemes ...string) Rule {
+	return valueRule(key, CodeFormat, func(v any) string {
+		s := toString(v)
+		u, err := url.Parse(s)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Sprintf("value %q is not a valid URL", s)
+		}
+		if len(schemes) > 0 && !slices.Contains(schemes, u.Scheme) {
+			return fmt.Sprintf("URL scheme %q is not one of %v", u.Scheme, schemes)
+		}
+		return ""
+	})
+}
+
+func IsHostPort(key string) Rule {
+	return valueRule(key, CodeFormat, func(v any) string {
+		s := toString(v)
+		_, port, err := net.SplitHostPort(s)
+		if err != nil {
+			return fmt.Sprintf("value %q is not a valid host:port", s)
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+			return fmt.Sprintf("value %q has invalid port %q", s, port)
+		}
+		return ""
+	})
+}
+
+func IsIP(key string) Rule {
+	return valueRule(key, CodeFormat, func(v any) string {
+		if s := toString(v); net.ParseIP(s) == nil {
+			return fmt.Sprintf("value %q is not a valid IP address", s)
+		}
+		return ""
+	})
+}
+
+func IsCIDR(key string) Rule {
+	return valueRule(key, CodeFormat, func(v any) string {
+		s := toString(v)
+		if _, _, err := net.ParseCIDR(s); err != nil {
+			return fmt.Sprintf("value %q is not a valid CIDR", s)
+		}
+		return ""
+	})
+}
+
+func IsPort(key string) Rule {
+	return valueRule(key, CodeRange, func(v any) string {
+		n, ok := toFloat64(v)
+		if !ok || n != math.Trunc(n) {
+			return fmt.Sprintf("value %v is not a valid port", v)
+		}
+		if n < 1 || n > 65535 {
+			return fmt.Sprintf("port %v is out of range [1, 65535]", n)
+		}
+		return ""
+	})
+}
+
+func IsDuration(key string, min, max time.Duration) Rule {
+	return valueRule(key, CodeRange, func(v any) string {
+		d, ok := toDuration(v)
+		if !ok {
+			return fmt.Sprintf("value %v is not a valid duration", v)
+		}
+		if d < min || (max > 0 && d > max) {
+			return fmt.Sprintf("duration %s is out of range [%s, %s]", d, min, durationBound(max))
+		}
+		return ""
+	})
+}
+
+func IsFilePath(key string) Rule {
+	return valueRule(key, CodeFormat, func(v any) string {
+		path := toString(v)
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Sprintf("file %q does not exist", path)
+		}
+		if info.IsDir() {
+			return fmt.Sprintf("path %q is a directory", path)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Sprintf("file %q is not readable", path)
+		}
+		_ = f.Close()
+		return ""
+	})
+}
+
+func IsEmail(key string) Rule {
+	return valueRule(key, CodeFormat, func(v any) string {
+		s := toString(v)
+		addr, err := mail.ParseAddress(s)
+		if err != nil || addr.Address != s {
+			return fmt.Sprintf("value %q is not a valid email address", s)
+		}
+		return ""
+	})
+}
+
+func MinLen(key string, n int) Rule {
+	return valueRule(key, CodeLength, func(v any) string {
+		l, ok := valueLen(v)
+		if !ok {
+			return fmt.Sprintf("value of type %T has no length", v)
+		}
+		if l < n {
+			return fmt.Sprintf("length %d is less than %d", l, n)
+		}
+		return ""
+	})
+}
+
+func MaxLen(key string, n int) Rule {
+	return valueRule(key, CodeLength, func(v any) string {
+		l, ok := valueLen(v)
+		if !ok {
+			return fmt.Sprintf("value of type %T has no length", v)
+		}
+		if l > n {
+			return fmt.Sprintf("length %d is greater than %d", l, n)
+		}
+		return ""
+	})
+}
+
+func NotEmpty(key string) Rule {
+	return valueRule(key, CodeRequired, func(v any) string {
+		if s, ok := v.(string); ok {
+			v = strings.TrimSpace(s)
+		}
+		if l, ok := valueLen(v); v == nil || (ok && l == 0) {
+			return "value must not be empty"
+		}
+		return ""
+	})
+}
+
+func IsTimeLayout(key, layout string) Rule {
+	return valueRule(key, CodeFormat, func(v any) string {
+		s := toString(v)
+		if _, err := time.Parse(layout, s); err != nil {
+			return fmt.Sprintf("value %q does not match time layout %q", s, layout)
+		}
+		return ""
+	})
+}
+
+func valueRule(key string, code ViolationCode, check func(v any) string) Rule {
+	return func(c *Config) error {
+		v, ok := c.find(key)
+		if !ok {
+			return nil
+		}
+		if msg := check(v); msg != "" {
+			return Violation{Key: key, Code: code, Value: v, Message: msg}
+		}
+		return nil
+	}
+}
+
+func valueLen(v any) (int, bool) {
+	switch val := v.(type) {
+	case string:
+		return utf8.RuneCountInString(val), true
+	case []any:
+		return len(val), true
+	case map[string]any:
+		return len(val), true
+	default:
+		return 0, false
+	}
+}
+
+func durationBound(d time.Duration) string {
+	if d <= 0 {
+		return "∞"
+	}
+	return d.String()
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValueRules(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	file := writeTestFile(t, dir, "ca.pem", "cert")
+
+	cases := []struct {
+		name string
+		rule Rule
+		val  any
+		want string
+	}{
+		{"url ok", IsURL("v", "https"), "https://example.com/path", ""},
+		{"url no host", IsURL("v"), "example.com", "is not a valid URL"},
+		{"url scheme", IsURL("v", "https"), "http://example.com", `URL scheme "http" is not one of [https]`},
+		{"hostport ok", IsHostPort("v"), "localhost:8080", ""},
+		{"hostport ipv6", IsHostPort("v"), "[::1]:443", ""},
+		{"hostport no port", IsHostPort("v"), "localhost", "is not a valid host:port"},
+		{"hostport bad port", IsHostPort("v"), "localhost:99999", `has invalid port "99999"`},
+		{"ip ok", IsIP("v"), "10.0.0.1", ""},
+		{"ip bad", IsIP("v"), "10.0.0.256", "is not a valid IP address"},
+		{"cidr ok", IsCIDR("v"), "10.0.0.0/8", ""},
+		{"cidr bad", IsCIDR("v"), "10.0.0.0", "is not a valid CIDR"},
+		{"port ok", IsPort("v"), "8080", ""},
+		{"port range", IsPort("v"), 0, "out of range [1, 65535]"},
+		{"port fraction", IsPort("v"), 80.5, "is not a valid port"},
+		{"duration ok", IsDuration("v", time.Second, time.Minute), "30s", ""},
+		{"duration ms", IsDuration("v", time.Second, 0), 1500, ""},
+		{"duration low", IsDuration("v", time.Second, 0), "10ms", "out of range [1s, ∞]"},
+		{"duration high", IsDuration("v", 0, time.Minute), "2m", "out of range [0s, 1m0s]"},
+		{"duration bad", IsDuration("v", 0, 0), "soon", "is not a valid duration"},
+		{"file ok", IsFilePath("v"), file, ""},
+		{"file missing", IsFilePath("v"), filepath.Join(dir, "nope"), "does not exist"},
+		{"file dir", IsFilePath("v"), dir, "is a directory"},
+		{"email ok", IsEmail("v"), "ops@example.com", ""},
+		{"email display name", IsEmail("v"), "Ops <ops@example.com>", "is not a valid email address"},
+		{"email bad", IsEmail("v"), "ops", "is not a valid email address"},
+		{"minlen string", MinLen("v", 3), "ab", "length 2 is less than 3"},
+		{"minlen runes", MinLen("v", 3), "абв", ""},
+		{"maxlen list", MaxLen("v", 1), []any{1, 2}, "length 2 is greater than 1"},
+		{"maxlen number", MaxLen("v", 1), 5, "has no length"},
+		{"notempty ok", NotEmpty("v"), "x", ""},
+		{"notempty blank", NotEmpty("v"), "  ", "must not be empty"},
+		{"notempty list", NotEmpty("v"), []any{}, "must not be empty"},
+		{"notempty nil", NotEmpty("v"), nil, "must not be empty"},
+		{"layout ok", IsTimeLayout("v", time.DateOnly), "2024-01-31", ""},
+		{"layout bad", IsTimeLayout("v", time.DateOnly), "31.01.2024", `does not match time layout "2006-01-02"`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.rule(newTestConfig(map[string]any{"v": tc.val}))
+			if tc.want == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestValueRules_MissingKey(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(map[string]any{})
+	err := cfg.Validate(IsURL("v"), IsPort("v"), NotEmpty("v"), MinLen("v", 1), IsFilePath("v"))
+	if err != nil {
+		t.Errorf("expected missing key to be skipped, got %v", err)
+	}
+}
+
+func TestValueRules_Details(t *testing.T) {
+	t.Parallel()
+	err := newTestConfig(map[string]any{"url": "nope"}).Validate(IsURL("url"))
+	v := err.(*ValidationError).Details[0]
+	if v.Key != "url" || v.Code != CodeFormat || v.Value != "nope" {
+		t.Errorf("unexpected violation: %+v", v)
+	}
+}
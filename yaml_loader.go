@@ -8,9 +8,11 @@ import (
 )
 
 type yamlLoader struct {
-	paths    []string
-	basePath string
-	optional bool
+	paths     []string
+	basePath  string
+	optional  bool
+	decrypted []string
+	source    string
 }
 
 func FromYAML(paths ...string) *yamlLoader {
@@ -31,6 +33,14 @@ func (l *yamlLoader) apply(b *builder) {
 	b.loaders = append(b.loaders, l)
 }
 
+func (l *yamlLoader) sensitiveKeys() []string {
+	return l.decrypted
+}
+
+func (l *yamlLoader) sourceName() string {
+	return l.source
+}
+
 func (l *yamlLoader) Load() (map[string]any, error) {
 	var details []LoadErrorDetail
 
@@ -52,12 +62,18 @@ func (l *yamlLoader) Load() (map[string]any, error) {
 			continue
 		}
 
-		var cfg map[string]any
-		if err = yaml.UnmarshalWithOptions(data, &cfg, yaml.UseJSONUnmarshaler()); err != nil {
-			return nil, errors.Join(ErrParseYAML, err)
+		l.source = path
+		cfg, err := parseYAML(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if isSOPSDocument(cfg) {
+			cfg, l.decrypted, err = decryptSOPSDocument(data, cfg, &sopsKeyring{})
+			return cfg, err
 		}
 
-		return normalizeMap(cfg), nil
+		return cfg, nil
 	}
 
 	if l.optional {
@@ -66,3 +82,15 @@ func (l *yamlLoader) Load() (map[string]any, error) {
 
 	return nil, &LoadError{Message: "no valid YAML configuration source found", Details: details}
 }
+
+func parseYAML(data []byte) (map[string]any, error) {
+	var cfg map[string]any
+	if err := yaml.UnmarshalWithOptions(data, &cfg, yaml.UseJSONUnmarshaler()); err != nil {
+		return nil, yamlError(err)
+	}
+	return normalizeMap(cfg), nil
+}
+
+func yamlError(err error) error {
+	return errors.Join(ErrParseYAML, errors.New(yaml.FormatError(err, false, false)))
+}